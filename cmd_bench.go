@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maze/internal/global"
+	"maze/internal/navigator"
+	"maze/internal/world"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const defaultBenchDir = "maps"
+
+// unknownOptimum Оптимум по критерию не известен: на карте с воротами и
+// охранниками mole планирует по тактам, а не по критерию
+const unknownOptimum = -2
+
+type benchParams struct {
+	dir      string
+	metric   string
+	repeat   int
+	csvFile  string
+	movement string
+}
+
+// benchRow Результат прогона одного роутера на одной карте
+type benchRow struct {
+	mapName  string
+	router   string
+	failed   bool // роутер упал
+	found    bool // найден маршрут до выхода
+	cost     int  // стоимость лучшего маршрута по критерию
+	optimum  int  // оптимальная стоимость (mole), -1 если выход недостижим, или unknownOptimum
+	elapsed  time.Duration
+	allocs   uint64 // число выделений памяти
+	bytes    uint64 // выделено байт
+	expanded int    // число зафиксированных раскрытий узлов (RecPointLists)
+}
+
+// runBench прогоняет все роутеры по всем картам каталога и сравнивает их
+// с оптимумом, который находит mole с учётом состояния карты
+func runBench(args []string) {
+
+	var p benchParams
+	fs := newFlagSet("bench", "[-dir maps] [flags]")
+	fs.StringVar(&p.dir, "dir", defaultBenchDir, "directory with maps (*.txt)")
+	fs.StringVar(&p.metric, "metric", string(navigator.DefaultOptions().Metric), "route metric: moves,cells,turns,cost")
+	fs.IntVar(&p.repeat, "n", 1, "runs per router and map, time and allocations are averaged")
+	fs.StringVar(&p.csvFile, "csv", "", "also write results as CSV to file (- for stdout instead of the table)")
+	fs.StringVar(&p.movement, "move", string(global.MovementRook), "movement model: rook,slide,step,king")
+	parseFlags(fs, args)
+
+	metric, err := global.ParseMetric(p.metric)
+	if err != nil {
+		fatalExit(err)
+	}
+	if p.repeat < 1 {
+		fatalExit("-n must be positive")
+	}
+	opts := navigator.DefaultOptions()
+	opts.Metric = metric
+
+	files, err := filepath.Glob(filepath.Join(p.dir, "*.txt"))
+	if err != nil {
+		fatalExit(err)
+	}
+	if len(files) == 0 {
+		fatalExit("no maps found in " + p.dir)
+	}
+	sort.Strings(files)
+
+	var rows []benchRow
+	for _, file := range files {
+		w := constructWorld(worldSource{fromFile: file, movement: p.movement})
+
+		optimum := -1
+		if w.IsTimed() {
+			optimum = unknownOptimum
+		} else if best, ok := navigator.FindBestRoute(w, opts); ok {
+			optimum = best.Cost.Get(metric)
+		}
+
+		for _, routerName := range navigator.RouterNames() {
+			row := benchRouter(w, routerName, opts, p.repeat)
+			row.mapName = filepath.Base(file)
+			row.optimum = optimum
+			rows = append(rows, row)
+		}
+	}
+
+	// CSV в stdout выводится вместо таблицы, чтобы его можно было разобрать
+	if p.csvFile != "-" {
+		printBenchTable(os.Stdout, rows, metric)
+	}
+
+	if p.csvFile != "" {
+		out := os.Stdout
+		if p.csvFile != "-" {
+			out, err = os.Create(p.csvFile)
+			if err != nil {
+				fatalExit(err)
+			}
+			defer func() { _ = out.Close() }()
+		}
+		if err := writeBenchCsv(out, rows, metric); err != nil {
+			fatalExit(err)
+		}
+	}
+}
+
+func benchRouter(w *world.World, routerName string, opts navigator.Options, repeat int) benchRow {
+
+	row := benchRow{router: routerName}
+	var routes []navigator.NavRoute
+	var before, after runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	begin := time.Now()
+	for i := 0; i < repeat; i++ {
+		var err error
+		if routes, err = findRoutesSafe(w, routerName, opts); err != nil {
+			row.failed = true
+			break
+		}
+	}
+	row.elapsed = time.Since(begin) / time.Duration(repeat)
+	runtime.ReadMemStats(&after)
+	row.allocs = (after.Mallocs - before.Mallocs) / uint64(repeat)
+	row.bytes = (after.TotalAlloc - before.TotalAlloc) / uint64(repeat)
+
+	if row.failed {
+		return row
+	}
+	// в зачёт идут только маршруты, которые проходят проверку по карте:
+	// например, роутеры без учёта времени идут сквозь закрытые ворота
+	row.expanded = expandedNodes(routerName, routes)
+	var valid []navigator.NavRoute
+	for _, route := range routes {
+		if world.ValidateRoute(w, route.Route) == nil {
+			valid = append(valid, route)
+		}
+	}
+	if best := navigator.SelectBest(valid, opts.Metric); best != -1 {
+		row.found = true
+		row.cost = valid[best].Cost.Get(opts.Metric)
+	}
+	return row
+}
+
+// expandedNodes возвращает число раскрытий узлов. Роутер ox ищет по каждому
+// критерию отдельно: для сравнения с другими роутерами считаем только поиск
+// по выбранному критерию - первый маршрут для каждой пары старта и выхода
+func expandedNodes(routerName string, routes []navigator.NavRoute) int {
+	expanded := 0
+	counted := map[[2]global.PointOnMap]bool{}
+	for _, route := range routes {
+		pair := [2]global.PointOnMap{route.Start, route.Exit}
+		if routerName == navigator.RouterOx && counted[pair] {
+			continue
+		}
+		counted[pair] = true
+		expanded += len(route.RecPointLists)
+	}
+	return expanded
+}
+
+// findRoutesSafe не даёт упавшему роутеру прервать весь прогон
+func findRoutesSafe(w *world.World, routerName string, opts navigator.Options) (routes []navigator.NavRoute, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("router %s failed: %v", routerName, r)
+		}
+	}()
+	return navigator.FindRoutes(w, routerName, opts), nil
+}
+
+func (row benchRow) status() string {
+	switch {
+	case row.failed:
+		return "panic"
+	case row.found:
+		return "yes"
+	}
+	return "no"
+}
+
+// costColumns возвращает стоимость, оптимум и превышение оптимума
+func (row benchRow) costColumns() (cost, optimum, excess string) {
+	cost, optimum, excess = "-", "-", "-"
+	if row.optimum == unknownOptimum {
+		optimum, excess = "?", "?"
+	} else if row.optimum != -1 {
+		optimum = strconv.Itoa(row.optimum)
+	}
+	if row.found {
+		cost = strconv.Itoa(row.cost)
+		if row.optimum >= 0 {
+			excess = "+" + strconv.Itoa(row.cost-row.optimum)
+		}
+	}
+	return
+}
+
+func printBenchTable(out io.Writer, rows []benchRow, metric global.Metric) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintf(tw, "MAP\tROUTER\tFOUND\t%s\tOPTIMUM\tEXCESS\tEXPANDED\tTIME\tALLOCS\tBYTES\t\n", metric)
+	for _, row := range rows {
+		cost, optimum, excess := row.costColumns()
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%v\t%d\t%d\t\n",
+			row.mapName, row.router, row.status(), cost, optimum, excess,
+			row.expanded, row.elapsed.Round(time.Microsecond), row.allocs, row.bytes)
+	}
+	_ = tw.Flush()
+
+	for _, row := range rows {
+		if row.optimum == unknownOptimum {
+			_, _ = fmt.Fprintf(out, "\n? - no optimum by %s: on maps with gates and guards mole minimizes arrival time\n", metric)
+			break
+		}
+	}
+}
+
+func writeBenchCsv(out io.Writer, rows []benchRow, metric global.Metric) error {
+	cw := csv.NewWriter(out)
+	header := []string{"map", "router", "found", string(metric), "optimum", "excess",
+		"expanded", "time_ns", "allocs", "bytes"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		cost, optimum, excess := row.costColumns()
+		record := []string{
+			row.mapName, row.router, row.status(), cost, optimum, excess,
+			strconv.Itoa(row.expanded),
+			strconv.FormatInt(row.elapsed.Nanoseconds(), 10),
+			strconv.FormatUint(row.allocs, 10),
+			strconv.FormatUint(row.bytes, 10),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	formatText = "text"
+	formatJson = "json"
+)
+
+// jsonWorld Карта в формате JSON: строки карты в текстовом формате
+type jsonWorld struct {
+	Width  int      `json:"width"`
+	Height int      `json:"height"`
+	Rows   []string `json:"rows"`
+	Legend []string `json:"legend,omitempty"`
+	Gates  []string `json:"gates,omitempty"`
+	Guards []string `json:"guards,omitempty"`
+	Grid   []string `json:"grid,omitempty"`
+}
+
+type convertParams struct {
+	worldSource
+	to string
+}
+
+// runConvert переводит карту между текстовым форматом и JSON. Текстовая карта
+// при этом выравнивается: лишние отступы убираются, строки дополняются до
+// ширины карты
+func runConvert(args []string) {
+
+	var p convertParams
+	fs := newFlagSet("convert", "[-f file | -i] [-to text|json]")
+	p.worldSource.register(fs)
+	fs.StringVar(&p.to, "to", formatJson, "output format: text,json")
+	parseFlags(fs, args)
+
+	w := constructWorld(p.worldSource)
+	width, height := w.GetSizes()
+	jw := jsonWorld{
+		Width:  width,
+		Height: height,
+		Rows:   w.Lines(),
+		Legend: w.Legend(),
+		Gates:  w.Gates(),
+		Guards: w.Guards(),
+		Grid:   w.Grid(),
+	}
+
+	switch p.to {
+	case formatText:
+		fmt.Println(strings.Join(append(jw.Rows, jw.sections()...), "\n"))
+	case formatJson:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(jw)
+		if err != nil {
+			fatalExit(err)
+		}
+	default:
+		fatalExit("unknown format: " + p.to)
+	}
+}
+
+func isJsonMap(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), "{")
+}
+
+func jsonMapToLines(text string) ([]string, error) {
+	var jw jsonWorld
+	if err := json.Unmarshal([]byte(text), &jw); err != nil {
+		return nil, err
+	}
+	if len(jw.Rows) == 0 {
+		return nil, fmt.Errorf("no rows in json map")
+	}
+	return append(jw.Rows, jw.sections()...), nil
+}
+
+// sections возвращает непустые секции карты в текстовом формате
+func (jw jsonWorld) sections() []string {
+	var lines []string
+	for _, section := range []struct {
+		name  string
+		lines []string
+	}{
+		{"legend", jw.Legend},
+		{"gates", jw.Gates},
+		{"guards", jw.Guards},
+		{"grid", jw.Grid},
+	} {
+		if len(section.lines) > 0 {
+			lines = append(lines, "", "["+section.name+"]")
+			lines = append(lines, section.lines...)
+		}
+	}
+	return lines
+}
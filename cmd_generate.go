@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"maze/internal/generate"
+	"os"
+	"time"
+)
+
+const (
+	defaultAlgorithm = generate.AlgorithmBacktracker
+	defaultWidth     = 21
+	defaultHeight    = 11
+)
+
+type generateParams struct {
+	algorithm string
+	seed      int64
+	width     int
+	height    int
+}
+
+func runGenerate(args []string) {
+
+	var p generateParams
+	fs := newFlagSet("generate", "[flags]")
+	fs.StringVar(&p.algorithm, "a", defaultAlgorithm, "algorithm: backtracker,prim,kruskal,wilson,division")
+	fs.Int64Var(&p.seed, "seed", 0, "seed for world generation (default: current time)")
+	fs.IntVar(&p.width, "width", defaultWidth, "width of generated world")
+	fs.IntVar(&p.height, "height", defaultHeight, "height of generated world")
+	parseFlags(fs, args)
+
+	seed := p.seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	text, err := generate.Generate(generate.Params{
+		Algorithm: p.algorithm,
+		Width:     p.width,
+		Height:    p.height,
+		Seed:      seed,
+	})
+	if err != nil {
+		fatalExit(err)
+	}
+	_, _ = fmt.Fprintln(os.Stderr, "Seed:", seed)
+	fmt.Println(text)
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"fmt"
+	"maze/internal/cli"
+	"maze/internal/global"
+	"maze/internal/navigator"
+	"maze/internal/navigator/routers/lynx"
+	"maze/internal/world"
+	"time"
+)
+
+const (
+	defaultRouter         = navigator.RouterFox
+	defaultAnimationSpeed = 3
+	useTraceOnMove        = true
+)
+
+const (
+	outputText = "text"
+	outputJson = "json"
+)
+
+type solveParams struct {
+	worldSource
+	animateRoute        int
+	animationSpeed      int
+	routerType          string
+	heuristic           string
+	metric              string
+	debugFlag           bool
+	debugAnimationFlag  bool
+	showRoutingTreeFlag bool
+	output              string
+	via                 string
+	anyOrder            bool
+	collectFlag         bool
+	k                   int
+}
+
+var params solveParams
+
+func runSolve(args []string) {
+
+	fs := newFlagSet("solve", "[-f file | -i] [flags]")
+	params.worldSource.register(fs)
+	fs.IntVar(&params.animateRoute, "r", -1, "animate route by number (if presented)")
+	fs.IntVar(&params.animationSpeed, "v", defaultAnimationSpeed, "set animation speed")
+	fs.StringVar(&params.routerType, "t", defaultRouter, "router type: hare,deer,hog,fox,wolf,ox,lynx,mole")
+	fs.StringVar(&params.heuristic, "heuristic", navigator.DefaultOptions().Heuristic, "heuristic for lynx router: manhattan,euclid,turns")
+	fs.StringVar(&params.metric, "metric", string(navigator.DefaultOptions().Metric), "route metric: moves,cells,turns,cost")
+	fs.BoolVar(&params.debugFlag, "debug", false, "debug mode: show banner and routing tree")
+	fs.BoolVar(&params.debugAnimationFlag, "D", false, "use debug animation (if provided by router)")
+	fs.BoolVar(&params.showRoutingTreeFlag, "T", false, "show routing tree")
+	fs.StringVar(&params.output, "o", outputText, "output format: text,json")
+	fs.IntVar(&params.k, "k", 0, "return N best loopless routes instead of router variants")
+	fs.StringVar(&params.via, "via", "", "visit checkpoints in order: \"x,y;x,y\" (! on the map - in any order)")
+	fs.BoolVar(&params.anyOrder, "any-order", false, "visit -via checkpoints in the best order")
+	fs.BoolVar(&params.collectFlag, "collect", false, "collect every item ($) before reaching the exit")
+	parseFlags(fs, args)
+
+	if params.breaks > 0 && params.routerType != navigator.RouterMole {
+		fatalExit("-break is supported by the mole router only, use -t mole")
+	}
+	if params.fuel > 0 && params.routerType != navigator.RouterMole {
+		fatalExit("-fuel is supported by the mole router only, use -t mole")
+	}
+	if params.k < 0 {
+		fatalExit("-k must not be negative")
+	}
+	if params.k > 0 && params.routerType == navigator.RouterMole {
+		fatalExit("-k is not supported by the mole router")
+	}
+
+	if isDebug() {
+		params.showRoutingTreeFlag = true
+	}
+
+	if params.output == outputJson {
+		solveToJson(params)
+		return
+	}
+	if params.output != outputText {
+		fatalExit("unknown output format: " + params.output)
+	}
+
+	cli.ClearScreen()
+	w := constructWorld(params.worldSource)
+	if isDebug() {
+		fmt.Println(cli.WarnStyle("DEBUG MODE: ON"))
+	}
+	world.PrintMe(w)
+
+	opts := constructOptions(params)
+	routerName, foundRoutes := findSolveRoutes(w, params, opts)
+
+	if params.animateRoute != -1 {
+		if params.animateRoute < 0 || params.animateRoute >= len(foundRoutes) {
+			fatalExit("Route not found")
+		}
+		result := foundRoutes[params.animateRoute]
+		animate(w, result, params.animationSpeed, params.debugAnimationFlag)
+		fmt.Println()
+		fmt.Println("Routes:")
+		showRoutes(w, foundRoutes)
+		exitIfNotFound(w, foundRoutes)
+		return
+	}
+	tree := navigator.BuildRoutingTree(w)
+
+	if params.showRoutingTreeFlag {
+		fmt.Println("Routing tree:")
+		navigator.PrintRoutingTree(tree)
+		if err := tree.ValidateBy(w.Rules()); err != nil {
+			fmt.Println(" ", cli.ErrorStyle("Validation: FALSE"))
+			fmt.Println(err)
+		} else {
+			fmt.Println(" ", cli.ShadowStyle("Validation: TRUE"))
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("Nodes:", len(tree))
+	fmt.Println("Router:", routerName)
+	fmt.Println("Routes:")
+	if len(foundRoutes) > 0 {
+		showRoutes(w, foundRoutes)
+		if best := navigator.SelectBest(foundRoutes, opts.Metric); best != -1 {
+			fmt.Printf("Best route by %s: #%d, exit %v\n\n", opts.Metric, best, foundRoutes[best].Exit)
+		}
+		if len(w.GetStarts()) > 1 {
+			showStarts(w, opts)
+		}
+		fmt.Print(cli.ShadowStyle("HELP: -r for animate route. Example:"))
+		fmt.Println(cli.ShadowStyle(" ./main solve -f path/to/map3.txt -v 5 -r 1"))
+	} else {
+		fmt.Println()
+		fmt.Println(" ", "No route!!")
+		fmt.Println()
+	}
+
+	exitIfNotFound(w, foundRoutes)
+}
+
+func solveToJson(params solveParams) {
+	w := constructWorld(params.worldSource)
+	opts := constructOptions(params)
+	routerName, foundRoutes := findSolveRoutes(w, params, opts)
+	printJson(w, routerName, foundRoutes, opts, params.showRoutingTreeFlag)
+	exitIfNotFound(w, foundRoutes)
+}
+
+// findSolveRoutes прокладывает маршруты роутером из параметров, а если заданы
+// обязательные точки (-via или ! на карте) или сбор предметов - через них
+func findSolveRoutes(w *world.World, params solveParams, opts navigator.Options) (string, []navigator.NavRoute) {
+
+	checkpoints, ordered := w.GetCheckpoints(), false
+	if params.via != "" {
+		if params.collectFlag {
+			fatalExit("-via is not supported with -collect")
+		}
+		var err error
+		if checkpoints, err = w.ParseCheckpoints(params.via); err != nil {
+			fatalExit(err)
+		}
+		ordered = !params.anyOrder
+	}
+	if len(checkpoints) == 0 && !params.collectFlag {
+		routerName := params.routerType
+		if opts.K > 0 {
+			routerName = navigator.RouterKShortest
+		}
+		return routerName, navigator.FindRoutes(w, params.routerType, opts)
+	}
+
+	switch {
+	case opts.K > 0:
+		fatalExit("-k is not supported with checkpoints and items")
+	case params.breaks > 0:
+		fatalExit("-break is not supported with checkpoints and items")
+	case params.fuel > 0:
+		fatalExit("-fuel is not supported with checkpoints and items")
+	case w.IsTimed():
+		fatalExit("checkpoints and items are not supported on maps with gates and guards")
+	case w.Movement() == global.MovementSlide:
+		fatalExit("checkpoints and items are not supported by slide movement")
+	}
+	if params.collectFlag {
+		return navigator.RouterCollect, navigator.FindCollectRoutes(w, opts)
+	}
+	return navigator.RouterVia, navigator.FindCheckpointRoutes(w, checkpoints, ordered, opts)
+}
+
+func constructOptions(params solveParams) navigator.Options {
+	metric, err := global.ParseMetric(params.metric)
+	if err != nil {
+		fatalExit(err)
+	}
+	opts := navigator.DefaultOptions()
+	heuristic, err := lynx.ParseHeuristic(params.heuristic)
+	if err != nil {
+		fatalExit(err)
+	}
+	opts.Heuristic = heuristic
+	opts.Metric = metric
+	opts.K = params.k
+	return opts
+}
+
+func animate(w *world.World, result navigator.NavRoute, speed int, useFrameAnimation bool) {
+
+	cli.ClearScreen()
+	speedValue := time.Duration(speed)
+	cmdString := cli.GetExecutedCommand()
+
+	route := *result.Route
+	if route.GetLength() > 0 {
+		start := route.Get(0)
+		w.SetPosition(world.GeoPosition{start[0], start[1]})
+	}
+	w.BreakWalls(result.Broken)
+	if w.IsTimed() {
+		// ворота и охранники меняются каждый такт: показываем по клетке за кадр
+		route = w.TimeSteps(route)
+	}
+	fuel := w.RouteFuel(&route)
+
+	t := 0
+	for i, node := range route.GetItems() {
+		if i > 0 {
+			t += w.StepTime(route.Get(i-1), node)
+		}
+		w.SetTime(t)
+		if err := w.Move(node[0], node[1], useTraceOnMove); err != nil {
+			panic(node)
+		}
+
+		lineForShow := 1
+		cli.SetCursorPosition(0, 0)
+		if isDebug() {
+			fmt.Println(cli.WarnStyle("DEBUG MODE: ON"))
+			lineForShow++
+		}
+		fmt.Println("Executed:", cmdString)
+		world.PrintMe(w)
+		if fuel != nil {
+			fmt.Printf("Fuel: %d/%d\n", fuel[i], w.Fuel())
+		}
+		fmt.Println("Router:", result.RouterName)
+		fmt.Println(" ", route.GetItems()[:i+1])
+		time.Sleep(time.Millisecond * 1500 / speedValue)
+
+		if !useFrameAnimation {
+			continue
+		}
+
+		func(iFrame int) {
+			wData := w.Pack()
+			for k := 0; k < 2; k++ {
+
+				if l := len(result.RecRouteFrames); 0 < l && iFrame < l-1 {
+					frm := result.RecRouteFrames[iFrame]
+					frmMin, frmMax := frm[0], frm[1]
+					frmMaxX, frmMaxY := frmMax[0]-1, frmMax[1]-1
+					frmMinX, frmMinY := frmMin[0]+1, frmMin[1]+1
+					w.SetRectangle(frmMinX, frmMinY, frmMaxX, frmMaxY)
+				}
+
+				if l := len(result.RecPointLists); 0 < l && iFrame < l-1 {
+					for _, p := range result.RecPointLists[iFrame] {
+						w.SetPoint(p[0], p[1], '?')
+					}
+
+					cli.SetCursorPosition(0, lineForShow)
+					world.PrintMapOnly(w)
+					time.Sleep(time.Millisecond * 1000 / speedValue)
+
+					w.Unpack(wData)
+					cli.SetCursorPosition(0, lineForShow)
+					world.PrintMapOnly(w)
+					time.Sleep(time.Millisecond * 1000 / speedValue)
+				}
+			}
+			w.Unpack(wData)
+		}(i)
+	}
+}
+
+func showRoutes(w *world.World, results []navigator.NavRoute) {
+	for n, route := range results {
+		validationSign := cli.ShadowStyle("Validation: TRUE")
+		err := world.ValidateRoute(w, route.Route)
+		if err != nil {
+			validationSign = cli.ErrorStyle("Validation: FALSE")
+		}
+		fmt.Println()
+		fmt.Println(" #", n, ":", route, validationSign)
+		if err != nil {
+			fmt.Println("   ", cli.ShadowStyle(err.Error()))
+		}
+	}
+	fmt.Println()
+}
+
+// showStarts выводит лучший маршрут из каждого старта и ближайший к выходу старт
+func showStarts(w *world.World, opts navigator.Options) {
+	startRoutes := navigator.FindStartRoutes(w, opts)
+	fmt.Printf("Starts by %s:\n", opts.Metric)
+	for n, route := range startRoutes {
+		if !route.IsFoundTarget() {
+			fmt.Printf("  #%d %v: %s\n", n, route.Start, cli.ErrorStyle("unreachable"))
+			continue
+		}
+		fmt.Printf("  #%d %v: %v\n", n, route.Start, route)
+	}
+	if nearest := navigator.SelectBest(startRoutes, opts.Metric); nearest != -1 {
+		route := startRoutes[nearest]
+		fmt.Printf("Nearest start: #%d %v, exit %v\n\n", nearest, route.Start, route.Exit)
+	}
+}
+
+func isDebug() bool {
+	return params.debugFlag
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"maze/internal/global"
+	"maze/internal/navigator"
+	"maze/internal/world"
+	"os"
+)
+
+type validateParams struct {
+	worldSource
+	route string
+}
+
+// runValidate проверяет карту (старт, выход, достижимость выхода) и, если
+// задан, маршрут по этой карте
+func runValidate(args []string) {
+
+	var p validateParams
+	fs := newFlagSet("validate", "[-f file | -i] [-route \"[x y] [x y] ...\"]")
+	p.worldSource.register(fs)
+	fs.StringVar(&p.route, "route", "", "route to validate, e.g. \"[1 1] [1 3] [5 3]\"")
+	parseFlags(fs, args)
+
+	w := constructWorld(p.worldSource)
+	if err := w.Check(); err != nil {
+		fatalExit(err)
+	}
+	fmt.Println("Map: OK")
+
+	if p.route != "" {
+		route, err := global.ParseRoute(p.route)
+		if err != nil {
+			fatalExit(err)
+		}
+		if err := world.ValidateRoute(w, route); err != nil {
+			fatalExit(err)
+		}
+		fmt.Println("Route: OK", route.CostBy(w.Rules()))
+	}
+
+	// достижимость проверяем роутером mole: он открывает двери ключами
+	reached := map[global.PointOnMap]bool{}
+	for _, route := range navigator.FindRoutes(w, navigator.RouterMole, navigator.DefaultOptions()) {
+		reached[route.Start] = reached[route.Start] || route.IsFoundTarget()
+	}
+	reachable := true
+	for n, start := range w.GetStartPoints() {
+		if !reached[start] {
+			reachable = false
+			if len(w.GetStarts()) > 1 {
+				fmt.Printf("Exit: unreachable from start #%d %v\n", n, start)
+			}
+		}
+	}
+	if !reachable {
+		if navigator.IsOutOfFuel(w) {
+			fmt.Printf("Exit: unreachable with fuel %d\n", w.Fuel())
+			os.Exit(ExitOutOfFuel)
+		}
+		if len(w.GetStarts()) == 1 {
+			fmt.Println("Exit: unreachable")
+		}
+		os.Exit(ExitTargetNotFound)
+	}
+	fmt.Println("Exit: reachable")
+}
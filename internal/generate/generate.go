@@ -0,0 +1,334 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"maze/internal/navigator"
+	"maze/internal/world"
+	"strings"
+)
+
+const (
+	AlgorithmBacktracker = "backtracker"
+	AlgorithmPrim        = "prim"
+	AlgorithmKruskal     = "kruskal"
+	AlgorithmWilson      = "wilson"
+	AlgorithmDivision    = "division"
+)
+
+// Algorithms все поддерживаемые алгоритмы генерации
+var Algorithms = []string{
+	AlgorithmBacktracker,
+	AlgorithmPrim,
+	AlgorithmKruskal,
+	AlgorithmWilson,
+	AlgorithmDivision,
+}
+
+// placementAttempts Сколько раз пробуем расставить старт и выход, пока выход
+// не окажется достижимым
+const placementAttempts = 100
+
+type Params struct {
+	Algorithm string
+	Width     int
+	Height    int
+	Seed      int64
+}
+
+// cell Клетка лабиринта в координатах ячеек: ячейка (x,y) занимает
+// на карте точку (2x+1, 2y+1), а проходы между ячейками лежат между ними
+type cell [2]int
+
+type maze struct {
+	rnd     *rand.Rand
+	geoMap  [][]byte
+	cols    int // число ячеек по горизонтали
+	rows    int // число ячеек по вертикали
+	visited map[cell]bool
+}
+
+// Generate строит лабиринт выбранным алгоритмом и возвращает его в текстовом
+// формате карт. Выход гарантированно достижим из старта
+func Generate(p Params) (string, error) {
+
+	if p.Width < 5 || p.Height < 5 {
+		return "", errors.New("maze size must be at least 5x5")
+	}
+
+	m := newMaze(p)
+	switch p.Algorithm {
+	case AlgorithmBacktracker:
+		m.backtracker()
+	case AlgorithmPrim:
+		m.prim()
+	case AlgorithmKruskal:
+		m.kruskal()
+	case AlgorithmWilson:
+		m.wilson()
+	case AlgorithmDivision:
+		m.division()
+	default:
+		return "", fmt.Errorf("unknown algorithm %q, expected one of %v", p.Algorithm, Algorithms)
+	}
+
+	for i := 0; i < placementAttempts; i++ {
+		text := m.place()
+		w, err := world.Construct(text)
+		if err != nil {
+			return "", err
+		}
+		tree := navigator.BuildRoutingTree(w)
+		start, target := w.GetStart().ToArray(), w.GetExit().ToArray()
+		if navigator.IsReachable(tree, start, target) {
+			return text, nil
+		}
+	}
+	return "", errors.New("unable to place reachable exit")
+}
+
+func newMaze(p Params) *maze {
+	m := &maze{
+		rnd:     rand.New(rand.NewSource(p.Seed)),
+		geoMap:  make([][]byte, p.Height),
+		cols:    (p.Width - 1) / 2,
+		rows:    (p.Height - 1) / 2,
+		visited: map[cell]bool{},
+	}
+	for y := range m.geoMap {
+		m.geoMap[y] = []byte(strings.Repeat(string(world.Wall), p.Width))
+	}
+	return m
+}
+
+// place ставит старт и выход в случайные различные ячейки
+func (m *maze) place() string {
+
+	geoMap := make([][]byte, len(m.geoMap))
+	for y, row := range m.geoMap {
+		geoMap[y] = append([]byte{}, row...)
+	}
+
+	start := m.randomCell()
+	exit := m.randomCell()
+	for exit == start {
+		exit = m.randomCell()
+	}
+	geoMap[2*start[1]+1][2*start[0]+1] = world.Me
+	geoMap[2*exit[1]+1][2*exit[0]+1] = world.Exit
+
+	lines := make([]string, len(geoMap))
+	for y, row := range geoMap {
+		lines[y] = string(row)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *maze) randomCell() cell {
+	return cell{m.rnd.Intn(m.cols), m.rnd.Intn(m.rows)}
+}
+
+// open освобождает ячейку
+func (m *maze) open(c cell) {
+	m.geoMap[2*c[1]+1][2*c[0]+1] = world.Space
+}
+
+// connect освобождает обе ячейки и проход между соседними ячейками
+func (m *maze) connect(a, b cell) {
+	m.open(a)
+	m.open(b)
+	m.geoMap[a[1]+b[1]+1][a[0]+b[0]+1] = world.Space
+}
+
+func (m *maze) neighbours(c cell) []cell {
+	var list []cell
+	for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		n := cell{c[0] + d[0], c[1] + d[1]}
+		if 0 <= n[0] && n[0] < m.cols && 0 <= n[1] && n[1] < m.rows {
+			list = append(list, n)
+		}
+	}
+	return list
+}
+
+func (m *maze) unvisitedNeighbours(c cell) []cell {
+	var list []cell
+	for _, n := range m.neighbours(c) {
+		if !m.visited[n] {
+			list = append(list, n)
+		}
+	}
+	return list
+}
+
+// backtracker рекурсивный поиск с возвратом (итеративно, через стек)
+func (m *maze) backtracker() {
+	first := m.randomCell()
+	m.visited[first] = true
+	m.open(first)
+	stack := []cell{first}
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		list := m.unvisitedNeighbours(current)
+		if len(list) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		next := list[m.rnd.Intn(len(list))]
+		m.visited[next] = true
+		m.connect(current, next)
+		stack = append(stack, next)
+	}
+}
+
+// prim рандомизированный алгоритм Прима: растим дерево от случайной ячейки,
+// присоединяя случайную соседнюю ячейку из фронтира
+func (m *maze) prim() {
+	first := m.randomCell()
+	m.visited[first] = true
+	m.open(first)
+
+	var frontier [][2]cell // пары (ячейка дерева, соседняя ячейка вне дерева)
+	for _, n := range m.neighbours(first) {
+		frontier = append(frontier, [2]cell{first, n})
+	}
+
+	for len(frontier) > 0 {
+		i := m.rnd.Intn(len(frontier))
+		edge := frontier[i]
+		frontier[i] = frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+
+		next := edge[1]
+		if m.visited[next] {
+			continue
+		}
+		m.visited[next] = true
+		m.connect(edge[0], next)
+		for _, n := range m.unvisitedNeighbours(next) {
+			frontier = append(frontier, [2]cell{next, n})
+		}
+	}
+}
+
+// kruskal рандомизированный алгоритм Краскала: перебираем проходы в случайном
+// порядке и открываем те, что соединяют разные множества ячеек
+func (m *maze) kruskal() {
+	parent := map[cell]cell{}
+	var find func(c cell) cell
+	find = func(c cell) cell {
+		p, ok := parent[c]
+		if !ok || p == c {
+			return c
+		}
+		root := find(p)
+		parent[c] = root
+		return root
+	}
+
+	var edges [][2]cell
+	for y := 0; y < m.rows; y++ {
+		for x := 0; x < m.cols; x++ {
+			c := cell{x, y}
+			m.open(c)
+			if x+1 < m.cols {
+				edges = append(edges, [2]cell{c, {x + 1, y}})
+			}
+			if y+1 < m.rows {
+				edges = append(edges, [2]cell{c, {x, y + 1}})
+			}
+		}
+	}
+	m.rnd.Shuffle(len(edges), func(i, j int) {
+		edges[i], edges[j] = edges[j], edges[i]
+	})
+
+	for _, edge := range edges {
+		a, b := find(edge[0]), find(edge[1])
+		if a != b {
+			parent[a] = b
+			m.connect(edge[0], edge[1])
+		}
+	}
+}
+
+// wilson алгоритм Уилсона: случайные блуждания со стиранием петель дают
+// равномерно распределённое остовное дерево
+func (m *maze) wilson() {
+	var cells []cell
+	for y := 0; y < m.rows; y++ {
+		for x := 0; x < m.cols; x++ {
+			cells = append(cells, cell{x, y})
+		}
+	}
+	m.rnd.Shuffle(len(cells), func(i, j int) {
+		cells[i], cells[j] = cells[j], cells[i]
+	})
+
+	m.visited[cells[0]] = true
+	m.open(cells[0])
+
+	for _, c := range cells[1:] {
+		if m.visited[c] {
+			continue
+		}
+		// блуждаем до дерева, запоминая последний выход из каждой ячейки
+		exitTo := map[cell]cell{}
+		for current := c; !m.visited[current]; {
+			list := m.neighbours(current)
+			next := list[m.rnd.Intn(len(list))]
+			exitTo[current] = next
+			current = next
+		}
+		for current := c; !m.visited[current]; current = exitTo[current] {
+			m.visited[current] = true
+			m.connect(current, exitTo[current])
+		}
+	}
+}
+
+// division рекурсивное деление: начинаем с пустого поля и делим его стенами
+// с одним проходом
+func (m *maze) division() {
+	for y := 1; y < 2*m.rows; y++ {
+		for x := 1; x < 2*m.cols; x++ {
+			m.geoMap[y][x] = world.Space
+		}
+	}
+	m.divide(0, 0, m.cols, m.rows)
+}
+
+// divide делит камеру из ячеек [x, x+w) x [y, y+h)
+func (m *maze) divide(x, y, w, h int) {
+	if w < 2 || h < 2 {
+		return
+	}
+
+	horizontal := h > w || (h == w && m.rnd.Intn(2) == 0)
+	if horizontal {
+		k := m.rnd.Intn(h - 1) // стена между рядами ячеек y+k и y+k+1
+		gap := x + m.rnd.Intn(w)
+		wallY := 2*(y+k) + 2
+		for mx := 2*x + 1; mx < 2*(x+w); mx++ {
+			if mx != 2*gap+1 {
+				m.geoMap[wallY][mx] = world.Wall
+			}
+		}
+		m.divide(x, y, w, k+1)
+		m.divide(x, y+k+1, w, h-k-1)
+		return
+	}
+
+	k := m.rnd.Intn(w - 1) // стена между столбцами ячеек x+k и x+k+1
+	gap := y + m.rnd.Intn(h)
+	wallX := 2*(x+k) + 2
+	for my := 2*y + 1; my < 2*(y+h); my++ {
+		if my != 2*gap+1 {
+			m.geoMap[my][wallX] = world.Wall
+		}
+	}
+	m.divide(x, y, k+1, h)
+	m.divide(x+k+1, y, w-k-1, h)
+}
@@ -0,0 +1,51 @@
+package generate
+
+import (
+	"maze/internal/navigator"
+	"maze/internal/world"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+
+	sizes := [][2]int{{5, 5}, {21, 11}, {30, 16}}
+
+	for _, algorithm := range Algorithms {
+		for _, size := range sizes {
+			for seed := int64(0); seed < 20; seed++ {
+				t.Run("Generate("+algorithm+")", func(t *testing.T) {
+					text, err := Generate(Params{
+						Algorithm: algorithm,
+						Width:     size[0],
+						Height:    size[1],
+						Seed:      seed,
+					})
+					if err != nil {
+						t.Fatalf("Failure on %v, seed %d: %v", size, seed, err)
+					}
+
+					w, err := world.Construct(text)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if width, height := w.GetSizes(); width != size[0] || height != size[1] {
+						t.Errorf("Failure: expected size %v, got %dx%d", size, width, height)
+					}
+					if _, ok := navigator.FindBestRoute(w, navigator.DefaultOptions()); !ok {
+						t.Errorf("Failure: no route on seed %d:\n%s", seed, text)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestGenerate_Seed(t *testing.T) {
+
+	p := Params{Algorithm: AlgorithmWilson, Width: 21, Height: 11, Seed: 42}
+	a, _ := Generate(p)
+	b, _ := Generate(p)
+	if a != b {
+		t.Errorf("Failure: same seed produced different mazes:\n%s\n\n%s", a, b)
+	}
+}
@@ -0,0 +1,238 @@
+package global
+
+import (
+	"fmt"
+)
+
+// Metric Критерий, по которому сравниваются маршруты
+type Metric string
+
+const (
+	MetricMoves Metric = "moves" // число перемещений
+	MetricCells Metric = "cells" // число пройденных клеток
+	MetricTurns Metric = "turns" // число поворотов
+	MetricCost  Metric = "cost"  // стоимость прохода по местности
+)
+
+// Metrics все поддерживаемые критерии
+var Metrics = []Metric{MetricMoves, MetricCells, MetricTurns, MetricCost}
+
+// CostFunc возвращает стоимость прохода по местности от точки до точки,
+// лежащих на одной прямой
+type CostFunc func(from, to PointOnMap) int
+
+// CellsCost местность без особенностей: каждая клетка стоит единицу
+// (по диагонали - тоже единица за клетку)
+func CellsCost(from, to PointOnMap) int {
+	dx, dy := abs(to[0]-from[0]), abs(to[1]-from[1])
+	if dx == dy {
+		return dx
+	}
+	return dx + dy
+}
+
+func ParseMetric(name string) (Metric, error) {
+	for _, m := range Metrics {
+		if string(m) == name {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown metric %q, expected one of %v", name, Metrics)
+}
+
+// RouteCost Стоимость маршрута по каждому из критериев
+type RouteCost struct {
+	Moves   int `json:"moves"`
+	Cells   int `json:"cells"`
+	Turns   int `json:"turns"`
+	Terrain int `json:"cost"`
+}
+
+func (c RouteCost) String() string {
+	return fmt.Sprintf("moves: %d, cells: %d, turns: %d, cost: %d", c.Moves, c.Cells, c.Turns, c.Terrain)
+}
+
+// Get возвращает стоимость по заданному критерию
+func (c RouteCost) Get(m Metric) int {
+	switch m {
+	case MetricMoves:
+		return c.Moves
+	case MetricCells:
+		return c.Cells
+	case MetricTurns:
+		return c.Turns
+	case MetricCost:
+		return c.Terrain
+	}
+	panic(fmt.Sprintf("Unknown metric: %s", m))
+}
+
+// Direction Направление перемещения: знаки смещения по x и y
+type Direction [2]int
+
+// DirectionOf вернёт направление перемещения между двумя точками
+func DirectionOf(from, to PointOnMap) Direction {
+	return Direction{sign(to[0] - from[0]), sign(to[1] - from[1])}
+}
+
+// StepCost возвращает стоимость одного перемещения по критерию. Для подсчёта
+// поворотов нужно направление предыдущего перемещения (нулевое для старта),
+// для стоимости по местности - функция стоимости местности
+func (m Metric) StepCost(prevDir Direction, from, to PointOnMap, terrain CostFunc) int {
+	switch m {
+	case MetricMoves:
+		return 1
+	case MetricCells:
+		return CellsCost(from, to)
+	case MetricCost:
+		return terrain(from, to)
+	case MetricTurns:
+		if dir := DirectionOf(from, to); prevDir != (Direction{}) && dir != prevDir {
+			return 1
+		}
+		return 0
+	}
+	panic(fmt.Sprintf("Unknown metric: %s", m))
+}
+
+// RouteRules Правила карты, без которых маршрут нельзя ни проверить,
+// ни посчитать: стоимость местности, мгновенные перемещения (телепорты),
+// перемещения через край карты и форма клеток
+type RouteRules struct {
+	Terrain  CostFunc                       // по умолчанию CellsCost или HexDistance
+	Cells    CostFunc                       // число клеток, по умолчанию CellsCost или HexDistance
+	IsHop    func(from, to PointOnMap) bool // по умолчанию телепортов нет
+	Movement Movement                       // по умолчанию rook
+	IsWrap   func(from, to PointOnMap) bool // по умолчанию края карты не сквозные
+	WrapSize func(p PointOnMap) (int, int)  // ширина и высота этажа точки, если края сквозные
+	Hex      bool                           // шестиугольная сетка (см. HexDirections)
+}
+
+func (rr RouteRules) terrain() CostFunc {
+	if rr.Terrain == nil && rr.Hex {
+		return HexDistance
+	}
+	if rr.Terrain == nil {
+		return CellsCost
+	}
+	return rr.Terrain
+}
+
+func (rr RouteRules) isHop(from, to PointOnMap) bool {
+	return rr.IsHop != nil && rr.IsHop(from, to)
+}
+
+func (rr RouteRules) isWrap(from, to PointOnMap) bool {
+	return rr.IsWrap != nil && rr.IsWrap(from, to)
+}
+
+// isLegal проверяет, что перемещение - телепорт, перемещение через край карты или
+// возможно в модели перемещения
+func (rr RouteRules) isLegal(from, to PointOnMap) bool {
+	if rr.isHop(from, to) || rr.isWrap(from, to) {
+		return true
+	}
+	if rr.Hex {
+		return rr.Movement.IsLegalHex(from, to)
+	}
+	return rr.Movement.IsLegal(from, to)
+}
+
+// direction возвращает направление перемещения с учётом краёв карты и
+// формы клеток
+func (rr RouteRules) direction(from, to PointOnMap) Direction {
+	if rr.isWrap(from, to) {
+		return WrapDirection(from, to)
+	}
+	if rr.Hex {
+		dir, _ := HexDirectionOf(from, to)
+		return dir
+	}
+	return DirectionOf(from, to)
+}
+
+// WrapDirection возвращает направление перемещения через край карты:
+// противоположное направлению напрямую
+func WrapDirection(from, to PointOnMap) Direction {
+	dir := DirectionOf(from, to)
+	return Direction{-dir[0], -dir[1]}
+}
+
+// StepCost возвращает стоимость одного перемещения по критерию. Телепорт
+// бесплатен по всем критериям
+func (rr RouteRules) StepCost(m Metric, prevDir Direction, from, to PointOnMap) int {
+	if rr.isHop(from, to) {
+		return 0
+	}
+	switch m {
+	case MetricCells:
+		if rr.Cells != nil {
+			return rr.Cells(from, to)
+		}
+		if rr.Hex {
+			return HexDistance(from, to)
+		}
+	case MetricTurns:
+		if dir := rr.direction(from, to); prevDir != (Direction{}) && dir != prevDir {
+			return 1
+		}
+		return 0
+	}
+	return m.StepCost(prevDir, from, to, rr.terrain())
+}
+
+// NextDirection возвращает направление после перемещения. После телепорта
+// движение продолжается в прежнем направлении
+func (rr RouteRules) NextDirection(prevDir Direction, from, to PointOnMap) Direction {
+	if rr.isHop(from, to) {
+		return prevDir
+	}
+	return rr.direction(from, to)
+}
+
+// Cost считает стоимость маршрута по всем критериям для местности без
+// особенностей
+func (r *Route) Cost() RouteCost {
+	return r.CostBy(RouteRules{})
+}
+
+// CostWith считает стоимость маршрута по всем критериям для заданной местности
+func (r *Route) CostWith(terrain CostFunc) RouteCost {
+	return r.CostBy(RouteRules{Terrain: terrain})
+}
+
+// CostBy считает стоимость маршрута по всем критериям по правилам карты
+func (r *Route) CostBy(rules RouteRules) RouteCost {
+	var cost RouteCost
+	var dir Direction
+	items := r.GetItems()
+	for i := 1; i < len(items); i++ {
+		from, to := items[i-1], items[i]
+		if from == to {
+			continue
+		}
+		cost.Moves += rules.StepCost(MetricMoves, dir, from, to)
+		cost.Cells += rules.StepCost(MetricCells, dir, from, to)
+		cost.Turns += rules.StepCost(MetricTurns, dir, from, to)
+		cost.Terrain += rules.StepCost(MetricCost, dir, from, to)
+		dir = rules.NextDirection(dir, from, to)
+	}
+	return cost
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -1,6 +1,7 @@
 package global
 
 import (
+	"container/heap"
 	"fmt"
 	"math"
 	"slices"
@@ -11,6 +12,7 @@ import (
 
 type (
 	PointOnMap    [2]int
+	PointOnMap3D  [3]int // x, y на этаже и этаж
 	PointList     []PointOnMap
 	RoutingStruct map[PointOnMap]PointList
 	PointRegistry map[PointOnMap]bool
@@ -34,6 +36,10 @@ func (p PointOnMap) String() string {
 	return fmt.Sprintf("(%d,%d)", p[0], p[1])
 }
 
+func (p PointOnMap3D) String() string {
+	return fmt.Sprintf("(%d,%d,%d)", p[0], p[1], p[2])
+}
+
 func (p PointOnMap) CalcDistance(toPoint PointOnMap) float64 {
 	x1, y1 := p[0], p[1]
 	x2, y2 := toPoint[0], toPoint[1]
@@ -166,38 +172,47 @@ func (r *Route) Reverse() Route {
 }
 
 func (r *Route) Unserialize(src string) *Route {
+	route, err := ParseRoute(src)
+	if err != nil {
+		panic(err)
+	}
+	*r = *route
+	return r
+}
+
+// ParseRoute разбирает маршрут в формате Serialize: "[x y] [x y] ..."
+func ParseRoute(src string) (*Route, error) {
 
 	if src == "" || src == "[]" {
-		r.items = nil
-		r.length = len(r.items)
-		return r
+		return &Route{}, nil
 	}
 
 	a := strings.Split(src, "]")
 	items := make([]PointOnMap, 0, len(a))
 	for _, v := range a {
-		if v == "" {
+		if strings.TrimSpace(v) == "" {
 			break // last empty
 		}
 
 		strPair := strings.Trim(v, "] [")
-		pair := strings.Split(strPair, " ")
+		pair := strings.Fields(strPair)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("bad point %q in route", strPair)
+		}
 
 		x, err := strconv.Atoi(pair[0])
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 
 		y, err := strconv.Atoi(pair[1])
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		items = append(items, PointOnMap{x, y})
 	}
 
-	r.items = items
-	r.length = len(r.items)
-	return r
+	return &Route{items: items, length: len(items)}, nil
 }
 
 func (r *Route) Serialize() string {
@@ -286,6 +301,12 @@ func (rf RouteFrame) FilterList(points PointList) PointList {
 }
 
 func (r *Route) Validate() error {
+	return r.ValidateBy(RouteRules{})
+}
+
+// ValidateBy проверяет, что каждое перемещение маршрута возможно в модели
+// перемещения или является телепортом
+func (r *Route) ValidateBy(rules RouteRules) error {
 	if r.length < 1 {
 		return nil
 	}
@@ -293,7 +314,7 @@ func (r *Route) Validate() error {
 	x0, y0 := items[0][0], items[0][1]
 	for i := 1; i < r.length; i++ {
 		x, y := items[i][0], items[i][1]
-		if x != x0 && y != y0 {
+		if items[i] != items[i-1] && !rules.isLegal(items[i-1], items[i]) {
 			return fmt.Errorf(
 				"bad node address in route (%d,%d) -> (%d,%d), step: %d",
 				x0, y0, x, y, i,
@@ -305,11 +326,17 @@ func (r *Route) Validate() error {
 }
 
 func (rs RoutingStruct) Validate() error {
+	return rs.ValidateBy(RouteRules{})
+}
+
+// ValidateBy проверяет, что все переходы дерева возможны в модели
+// перемещения или являются телепортами
+func (rs RoutingStruct) ValidateBy(rules RouteRules) error {
 	for node, toNodes := range rs {
 		x0, y0 := node[0], node[1]
 		for i := 0; i < len(toNodes); i++ {
 			x, y := toNodes[i][0], toNodes[i][1]
-			if x != x0 && y != y0 {
+			if !rules.isLegal(node, toNodes[i]) {
 				return fmt.Errorf(
 					"bad node address (%d,%d) -> (%d,%d), node: %v",
 					x0, y0, x, y, node,
@@ -345,3 +372,59 @@ func NewRouteFromSlice(slice [][2]int) Route {
 		length: len(slice),
 	}
 }
+
+// PriorityQueue очередь с приоритетом: первым извлекается элемент с наименьшей
+// стоимостью, при равной стоимости - добавленный раньше (стабильные маршруты)
+type PriorityQueue[T any] struct {
+	items queueItems[T]
+	seq   int
+}
+
+type queueItem[T any] struct {
+	value T
+	cost  float64
+	seq   int
+}
+
+type queueItems[T any] []queueItem[T]
+
+func (q *PriorityQueue[T]) Len() int {
+	return len(q.items)
+}
+
+func (q *PriorityQueue[T]) Push(value T, cost float64) {
+	q.seq++
+	heap.Push(&q.items, queueItem[T]{value: value, cost: cost, seq: q.seq})
+}
+
+func (q *PriorityQueue[T]) Pop() (T, float64) {
+	item := heap.Pop(&q.items).(queueItem[T])
+	return item.value, item.cost
+}
+
+func (qi queueItems[T]) Len() int {
+	return len(qi)
+}
+
+func (qi queueItems[T]) Less(i, j int) bool {
+	if qi[i].cost != qi[j].cost {
+		return qi[i].cost < qi[j].cost
+	}
+	return qi[i].seq < qi[j].seq
+}
+
+func (qi queueItems[T]) Swap(i, j int) {
+	qi[i], qi[j] = qi[j], qi[i]
+}
+
+func (qi *queueItems[T]) Push(x any) {
+	*qi = append(*qi, x.(queueItem[T]))
+}
+
+func (qi *queueItems[T]) Pop() any {
+	items := *qi
+	last := len(items) - 1
+	item := items[last]
+	*qi = items[:last]
+	return item
+}
@@ -110,3 +110,158 @@ func TestRoute_ToFrames(t *testing.T) {
 		})
 	}
 }
+
+func TestPriorityQueue(t *testing.T) {
+
+	q := &PriorityQueue[string]{}
+	q.Push("c", 3)
+	q.Push("a1", 1)
+	q.Push("b", 2)
+	q.Push("a2", 1)
+
+	expected := []string{"a1", "a2", "b", "c"}
+	for _, value := range expected {
+		t.Run("TestPriorityQueue()", func(t *testing.T) {
+			if result, _ := q.Pop(); result != value {
+				t.Errorf("Failure: expected %q, got %q", value, result)
+			}
+		})
+	}
+	if q.Len() != 0 {
+		t.Errorf("Failure: queue is not empty")
+	}
+}
+
+func TestRoute_Cost(t *testing.T) {
+
+	type testCase struct {
+		in  string
+		out RouteCost
+	}
+
+	testCases := []testCase{
+		{"[]", RouteCost{}},
+		{"[3 4]", RouteCost{}},
+		{"[3 4] [0 4]", RouteCost{Moves: 1, Cells: 3, Turns: 0, Terrain: 3}},
+		{"[3 4] [0 4] [0 0] [5 0]", RouteCost{Moves: 3, Cells: 12, Turns: 2, Terrain: 12}},
+		{"[0 0] [0 5] [0 2]", RouteCost{Moves: 2, Cells: 8, Turns: 1, Terrain: 8}},
+	}
+
+	for _, tc := range testCases {
+		t.Run("TestRoute_Cost()", func(t *testing.T) {
+			result := (&Route{}).Unserialize(tc.in).Cost()
+			if result != tc.out {
+				t.Errorf("Failure on %s: expected %v, got %v", tc.in, tc.out, result)
+			}
+		})
+	}
+}
+
+func TestRoute_CostBy(t *testing.T) {
+
+	// телепорт между [3 0] и [7 5]
+	rules := RouteRules{IsHop: func(from, to PointOnMap) bool {
+		return from == PointOnMap{3, 0} && to == PointOnMap{7, 5}
+	}}
+
+	type testCase struct {
+		in    string
+		out   RouteCost
+		valid bool
+	}
+
+	testCases := []testCase{
+		{"[0 0] [3 0] [7 5]", RouteCost{Moves: 1, Cells: 3, Turns: 0, Terrain: 3}, true},
+		{"[0 0] [3 0] [7 5] [9 5]", RouteCost{Moves: 2, Cells: 5, Turns: 0, Terrain: 5}, true},
+		{"[0 0] [3 0] [7 5] [7 9]", RouteCost{Moves: 2, Cells: 7, Turns: 1, Terrain: 7}, true},
+		{"[0 0] [3 0] [8 5]", RouteCost{Moves: 2, Cells: 8, Turns: 1, Terrain: 8}, false},
+		{"[0 0] [3 0] [8 6]", RouteCost{Moves: 2, Cells: 14, Turns: 1, Terrain: 14}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run("TestRoute_CostBy()", func(t *testing.T) {
+			route := (&Route{}).Unserialize(tc.in)
+			if result := route.CostBy(rules); result != tc.out {
+				t.Errorf("Failure on %s: expected %v, got %v", tc.in, tc.out, result)
+			}
+			if err := route.ValidateBy(rules); tc.valid != (err == nil) {
+				t.Errorf("Failure on %s: %v", tc.in, err)
+			}
+		})
+	}
+}
+
+func TestMovement_IsLegal(t *testing.T) {
+
+	type testCase struct {
+		movement Movement
+		to       PointOnMap
+		out      bool
+	}
+
+	testCases := []testCase{
+		{MovementRook, PointOnMap{0, 5}, true},
+		{MovementRook, PointOnMap{1, 1}, false},
+		{MovementSlide, PointOnMap{5, 0}, true},
+		{MovementStep, PointOnMap{0, 1}, true},
+		{MovementStep, PointOnMap{0, 2}, false},
+		{MovementStep, PointOnMap{1, 1}, false},
+		{MovementKing, PointOnMap{1, 1}, true},
+		{MovementKing, PointOnMap{2, 2}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run("Movement.IsLegal()", func(t *testing.T) {
+			if result := tc.movement.IsLegal(PointOnMap{0, 0}, tc.to); result != tc.out {
+				t.Errorf("Failure on %s %v: expected %v, got %v", tc.movement, tc.to, tc.out, result)
+			}
+		})
+	}
+}
+
+func TestHexDirectionOf(t *testing.T) {
+
+	type testCase struct {
+		to       PointOnMap
+		dir      Direction
+		straight bool
+		distance int
+	}
+
+	// из чётной строки: нечётные строки сдвинуты вправо
+	testCases := []testCase{
+		{PointOnMap{3, 2}, Direction{1, 0}, true, 1},
+		{PointOnMap{2, 1}, Direction{1, -1}, true, 1},
+		{PointOnMap{1, 1}, Direction{0, -1}, true, 1},
+		{PointOnMap{3, 0}, Direction{1, -1}, true, 2},
+		{PointOnMap{1, 4}, Direction{-1, 1}, true, 2},
+		{PointOnMap{2, 0}, Direction{}, false, 2},
+	}
+
+	from := PointOnMap{2, 2}
+	for _, tc := range testCases {
+		t.Run("HexDirectionOf()", func(t *testing.T) {
+			if dir, ok := HexDirectionOf(from, tc.to); ok != tc.straight || dir != tc.dir {
+				t.Errorf("Failure on %v: expected %v %v, got %v %v", tc.to, tc.dir, tc.straight, dir, ok)
+			}
+			if distance := HexDistance(from, tc.to); distance != tc.distance {
+				t.Errorf("Failure on %v: expected distance %d, got %d", tc.to, tc.distance, distance)
+			}
+			if tc.straight && HexNeighbor(from, tc.dir) == from {
+				t.Errorf("Failure on %v: neighbor is the same cell", tc.to)
+			}
+			if legal := MovementStep.IsLegalHex(from, tc.to); legal != (tc.straight && tc.distance == 1) {
+				t.Errorf("Failure on %v: expected step legal %v, got %v", tc.to, !legal, legal)
+			}
+		})
+	}
+
+	route := (&Route{}).Unserialize("[2 2] [3 0] [1 0]")
+	rules := RouteRules{Hex: true}
+	if cost := route.CostBy(rules); cost != (RouteCost{Moves: 2, Cells: 4, Turns: 1, Terrain: 4}) {
+		t.Errorf("Failure on %v: got %v", route.GetItems(), cost)
+	}
+	if err := (&Route{}).Unserialize("[2 2] [2 0]").ValidateBy(rules); err == nil {
+		t.Errorf("Failure on [2 2] [2 0]: column is not a hex line")
+	}
+}
@@ -0,0 +1,57 @@
+package global
+
+// Шестиугольная сетка: клетки карты остаются на своих местах в тексте, а
+// нечётные строки сдвинуты на полклетки вправо. Направления и прямые
+// считаются в осевых координатах (q, r): r - строка, q растёт вдоль строки.
+// Через каждую клетку проходят три прямые: строка и две наклонные
+
+// HexDirections шесть направлений в осевых координатах: вправо, влево,
+// вверх-влево, вниз-вправо, вверх-вправо, вниз-влево
+var HexDirections = []Direction{{1, 0}, {-1, 0}, {0, -1}, {0, 1}, {1, -1}, {-1, 1}}
+
+// ToAxial возвращает осевые координаты точки
+func ToAxial(p PointOnMap) [2]int {
+	return [2]int{p[0] - (p[1]-p[1]&1)/2, p[1]}
+}
+
+// FromAxial возвращает точку по осевым координатам
+func FromAxial(a [2]int) PointOnMap {
+	return PointOnMap{a[0] + (a[1]-a[1]&1)/2, a[1]}
+}
+
+// HexNeighbor возвращает соседнюю клетку в направлении `dir`
+func HexNeighbor(p PointOnMap, dir Direction) PointOnMap {
+	a := ToAxial(p)
+	return FromAxial([2]int{a[0] + dir[0], a[1] + dir[1]})
+}
+
+// HexDirectionOf вернёт направление перемещения между точками, если они
+// лежат на одной прямой шестиугольной сетки
+func HexDirectionOf(from, to PointOnMap) (Direction, bool) {
+	a, b := ToAxial(from), ToAxial(to)
+	dq, dr := b[0]-a[0], b[1]-a[1]
+	if dq != 0 && dr != 0 && dq != -dr {
+		return Direction{}, false
+	}
+	return Direction{sign(dq), sign(dr)}, true
+}
+
+// HexDistance возвращает число шагов между клетками шестиугольной сетки
+func HexDistance(from, to PointOnMap) int {
+	a, b := ToAxial(from), ToAxial(to)
+	dq, dr := b[0]-a[0], b[1]-a[1]
+	return (abs(dq) + abs(dr) + abs(dq+dr)) / 2
+}
+
+// IsLegalHex проверяет, что перемещение на шестиугольной сетке возможно в
+// этой модели: по одной из трёх прямых, а для step и king - на соседнюю
+// клетку (на такой сетке у клетки только шесть соседей, king - тот же step)
+func (m Movement) IsLegalHex(from, to PointOnMap) bool {
+	if _, ok := HexDirectionOf(from, to); !ok {
+		return false
+	}
+	if m == MovementStep || m == MovementKing {
+		return HexDistance(from, to) == 1
+	}
+	return true
+}
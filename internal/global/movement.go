@@ -0,0 +1,40 @@
+package global
+
+import (
+	"fmt"
+)
+
+// Movement Модель перемещения по карте
+type Movement string
+
+const (
+	MovementRook  Movement = "rook"  // по прямой с остановкой в любой клетке
+	MovementSlide Movement = "slide" // по прямой до упора (лёд)
+	MovementStep  Movement = "step"  // на одну клетку по вертикали или горизонтали
+	MovementKing  Movement = "king"  // на одну клетку, в том числе по диагонали
+)
+
+// Movements все поддерживаемые модели перемещения
+var Movements = []Movement{MovementRook, MovementSlide, MovementStep, MovementKing}
+
+func ParseMovement(name string) (Movement, error) {
+	for _, m := range Movements {
+		if string(m) == name {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown movement %q, expected one of %v", name, Movements)
+}
+
+// IsLegal проверяет, что перемещение между точками возможно в этой модели
+// (без учёта карты)
+func (m Movement) IsLegal(from, to PointOnMap) bool {
+	dx, dy := abs(to[0]-from[0]), abs(to[1]-from[1])
+	switch m {
+	case MovementStep:
+		return dx+dy == 1
+	case MovementKing:
+		return max(dx, dy) == 1
+	}
+	return dx == 0 || dy == 0
+}
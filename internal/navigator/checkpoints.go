@@ -0,0 +1,111 @@
+package navigator
+
+import (
+	. "maze/internal/global"
+	"maze/internal/navigator/tour"
+	"maze/internal/world"
+	"slices"
+)
+
+const (
+	// RouterVia Имя поставщика маршрутов через обязательные точки
+	RouterVia = "via"
+
+	// RouterCollect Имя поставщика маршрутов со сбором всех предметов
+	RouterCollect = "collect"
+)
+
+// FindCheckpointRoutes возвращает по маршруту из каждого старта к каждому
+// выходу через все обязательные точки: в заданном порядке (ordered) или в
+// лучшем
+func FindCheckpointRoutes(w *world.World, checkpoints PointList, ordered bool, opts Options) []NavRoute {
+	return planRoutes(w, RouterVia, checkpoints, ordered, opts)
+}
+
+// FindCollectRoutes возвращает по маршруту из каждого старта к каждому
+// выходу, собирающему все предметы (и проходящему обязательные точки карты)
+// в лучшем порядке
+func FindCollectRoutes(w *world.World, opts Options) []NavRoute {
+	return planRoutes(w, RouterCollect, append(w.GetItemPoints(), w.GetCheckpoints()...), false, opts)
+}
+
+// planRoutes прокладывает маршруты через все точки. Кратчайшие пути между
+// точками находит поиск Дейкстры по графу локаций, порядок обхода - заданный
+// или лучший (см. tour.Order).
+// Недостижимые сочетания старта и выхода пропускаются
+func planRoutes(w *world.World, routerName string, checkpoints PointList, ordered bool, opts Options) []NavRoute {
+
+	starts := w.GetStartPoints()
+	exits := w.GetExitPoints()
+	graph := BuildRoutingGraph(w, starts, append(slices.Clone(checkpoints), exits...))
+	rules := w.Rules()
+
+	// пути от каждой обязательной точки считаем один раз для всех стартов
+	legs := make([]pathTree, len(checkpoints))
+	for i, cp := range checkpoints {
+		legs[i] = shortestPaths(graph, rules, opts.Metric, PointList{cp})
+	}
+
+	var results []NavRoute
+	for _, start := range starts {
+		fromStart := shortestPaths(graph, rules, opts.Metric, PointList{start})
+		trees := append([]pathTree{fromStart}, legs...)
+		for _, exit := range exits {
+			// матрица расстояний: старт, обязательные точки, выход
+			points := append(append(PointList{start}, checkpoints...), exit)
+			dist := make([][]int, len(points))
+			for i := range points {
+				dist[i] = make([]int, len(points))
+				for j, p := range points {
+					dist[i][j] = tour.Unreachable
+					if i == len(points)-1 {
+						continue
+					}
+					if c, ok := trees[i].cost(p); ok {
+						dist[i][j] = c
+					}
+				}
+			}
+
+			var order []int
+			length := 0
+			if ordered {
+				for i := range checkpoints {
+					order = append(order, i+1)
+				}
+				length = tour.Length(dist, order)
+			} else {
+				order, length = tour.Order(dist)
+			}
+			if length >= tour.Unreachable {
+				continue
+			}
+
+			result := NavRoute{
+				RouterName: routerName,
+				Route:      &Route{},
+				Start:      start,
+				Exit:       exit,
+			}
+			result.Route.Add(start)
+			prev := 0
+			for _, i := range append(order, len(points)-1) {
+				leg := trees[prev].chain(points[i])
+				// цепочка ведёт от конца участка к его началу
+				for k := len(leg) - 2; k >= 0; k-- {
+					result.Route.Add(leg[k])
+				}
+				if i < len(points)-1 {
+					result.Via = append(result.Via, points[i])
+				}
+				prev = i
+			}
+			unfolded := w.Unfold(*result.Route)
+			result.Route = &unfolded
+			result.Cost = result.Route.CostBy(w.Rules())
+			result.Keys = w.CollectKeys(result.Route)
+			results = append(results, result)
+		}
+	}
+	return results
+}
@@ -0,0 +1,115 @@
+package navigator
+
+import (
+	. "maze/internal/global"
+	"maze/internal/world"
+	"slices"
+	"testing"
+)
+
+func TestFindCheckpointRoutes(t *testing.T) {
+
+	w, err := world.Construct(`
+		wwwwwwwwwwww
+		w!  @   ! Qw
+		wwwwwwwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.Metric = MetricCells
+
+	testCases := []struct {
+		name        string
+		checkpoints PointList
+		ordered     bool
+		route       string
+	}{
+		{"any order", w.GetCheckpoints(), false, "[4 1] [1 1] [8 1] [10 1]"},
+		{"ordered", PointList{{8, 1}, {1, 1}}, true, "[4 1] [8 1] [1 1] [10 1]"},
+		{"single", PointList{{8, 1}}, true, "[4 1] [8 1] [10 1]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			routes := FindCheckpointRoutes(w, tc.checkpoints, tc.ordered, opts)
+			expected := (&Route{}).Unserialize(tc.route)
+			if len(routes) != 1 || !slices.Equal(routes[0].Route.GetItems(), expected.GetItems()) {
+				t.Fatalf("Failure on %s: expected %s, got %v", tc.name, tc.route, routes)
+			}
+			if err := world.ValidateRoute(w, routes[0].Route); err != nil {
+				t.Errorf("Failure on %s: %v", tc.name, err)
+			}
+			cells := w.RouteCells(routes[0].Route)
+			for _, cp := range tc.checkpoints {
+				if !cells[cp] {
+					t.Errorf("Failure on %s: checkpoint %v is not visited", tc.name, cp)
+				}
+			}
+			if !tc.ordered && len(routes[0].Via) != len(tc.checkpoints) {
+				t.Errorf("Failure on %s: expected visiting order, got %v", tc.name, routes[0].Via)
+			}
+		})
+	}
+
+	// обязательная точка за стеной недостижима
+	w, err = world.Construct(`
+		wwwwwwww
+		w@  Qw!w
+		wwwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routes := FindCheckpointRoutes(w, w.GetCheckpoints(), false, opts); len(routes) != 0 {
+		t.Errorf("Failure: expected no routes, got %v", routes)
+	}
+}
+
+func TestFindCollectRoutes(t *testing.T) {
+
+	opts := DefaultOptions()
+	opts.Metric = MetricCells
+
+	testCases := []struct {
+		name  string
+		in    string
+		cells int
+		via   PointList
+	}{
+		{"exact", "w$ @ $ Qw", 8, PointList{{1, 0}, {5, 0}}},
+		// предметов больше MaxExact: порядок ищет эвристика
+		{"heuristic", "w$$$$$$$$$@$$$$$$$$$Qw", 28, nil},
+		{"no items", "w@  Qw", 3, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := world.Construct(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			routes := FindCollectRoutes(w, opts)
+			if len(routes) != 1 || routes[0].Cost.Get(MetricCells) != tc.cells {
+				t.Fatalf("Failure on %s: expected %d cells, got %v", tc.name, tc.cells, routes)
+			}
+			if err := world.ValidateRoute(w, routes[0].Route); err != nil {
+				t.Errorf("Failure on %s: %v", tc.name, err)
+			}
+			cells := w.RouteCells(routes[0].Route)
+			for _, item := range w.GetItemPoints() {
+				if !cells[item] {
+					t.Errorf("Failure on %s: item %v is not collected", tc.name, item)
+				}
+			}
+			if len(routes[0].Via) != len(w.GetItemPoints()) {
+				t.Errorf("Failure on %s: expected visiting order of all items, got %v", tc.name, routes[0].Via)
+			}
+			if tc.via != nil && !slices.Equal(routes[0].Via, tc.via) {
+				t.Errorf("Failure on %s: expected order %v, got %v", tc.name, tc.via, routes[0].Via)
+			}
+		})
+	}
+}
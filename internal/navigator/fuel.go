@@ -0,0 +1,23 @@
+package navigator
+
+import (
+	"maze/internal/world"
+)
+
+// IsOutOfFuel проверяет, когда маршрут до выхода не найден, что выход
+// недостижим только из-за запаса топлива: с неограниченным топливом роутер
+// mole маршрут находит
+func IsOutOfFuel(w *world.World) bool {
+	if w.Fuel() == 0 {
+		return false
+	}
+	fuel := w.Fuel()
+	defer w.SetFuel(fuel)
+	w.SetFuel(0)
+	for _, route := range findStateRoutes(w, DefaultOptions()) {
+		if route.IsFoundTarget() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package navigator
+
+import (
+	"fmt"
+	. "maze/internal/global"
+	"maze/internal/world"
+	"slices"
+	"testing"
+)
+
+func TestFindRoutes_MoleFuel(t *testing.T) {
+
+	// напрямую до выхода 19 клеток, заправка - в тупике посередине
+	w, err := world.Construct(`
+		wwwwwwwwwwwwwwwwwwwwww
+		w@                  Qw
+		wwwwwwwwwwFwwwwwwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		fuel  int
+		route string
+	}{
+		{0, "[1 1] [20 1]"},
+		{19, "[1 1] [20 1]"},
+		{12, "[1 1] [10 1] [10 2] [10 1] [20 1]"},
+		{10, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("fuel %d", tc.fuel), func(t *testing.T) {
+			w.SetFuel(tc.fuel)
+			routes := FindRoutes(w, RouterMole, DefaultOptions())
+			if tc.route == "" {
+				if len(routes) != 0 || !IsOutOfFuel(w) {
+					t.Errorf("Failure on fuel %d: expected out of fuel, got %v", tc.fuel, routes)
+				}
+				return
+			}
+			expected := (&Route{}).Unserialize(tc.route)
+			if len(routes) != 1 || !slices.Equal(routes[0].Route.GetItems(), expected.GetItems()) {
+				t.Errorf("Failure on fuel %d: expected %s, got %v", tc.fuel, tc.route, routes)
+			} else if err := world.ValidateRoute(w, routes[0].Route); err != nil {
+				t.Errorf("Failure on fuel %d: %v", tc.fuel, err)
+			}
+		})
+	}
+	if w.Fuel() != 10 {
+		t.Errorf("Failure: IsOutOfFuel() must restore fuel, got %d", w.Fuel())
+	}
+
+	// выход за стеной недостижим при любом топливе
+	w, err = world.Construct("w@ wQw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetFuel(10)
+	if IsOutOfFuel(w) {
+		t.Errorf("Failure: exit behind a wall is not a fuel shortage")
+	}
+}
@@ -0,0 +1,108 @@
+package navigator
+
+import (
+	. "maze/internal/global"
+	"maze/internal/navigator/routers/mole"
+	"maze/internal/world"
+)
+
+// costScale Множитель основного критерия, как в ox: при равной основной
+// стоимости выбирается маршрут с меньшим числом клеток
+const costScale = 1 << 20
+
+// keyState Что кроме точки определяет состояние поиска по карте с дверями,
+// сносимыми стенами и ограниченным топливом
+type keyState struct {
+	keys   world.KeySet
+	dir    Direction // направление прихода, только для поворотов
+	broken int       // сколько стен уже снесено
+	fuel   int       // остаток топлива, только при ограниченном топливе
+}
+
+// keySpace Пространство состояний (позиция, собранные ключи, снесённые стены,
+// остаток топлива). Ключи - узлы поиска наравне с выходом: зайдя на клетку с
+// ключом, открываем его двери. Пока запас сносов не исчерпан, можно шагнуть в
+// соседнюю стену. Перемещение, на которое не хватает топлива, невозможно
+type keySpace struct {
+	w       *world.World
+	metric  Metric
+	targets PointList // выход и клетки с ключами
+	exit    PointOnMap
+}
+
+func (ks *keySpace) Next(s mole.State[keyState]) []mole.Move[keyState] {
+
+	ks.w.OpenDoors(s.Data.keys)
+	defer ks.w.OpenDoors(0)
+
+	from := s.Point
+	nextMoves := ks.w.FindNextMoves(from[0], from[1], ks.targets)
+	firstBreak := len(nextMoves) // сносы стен идут после обычных перемещений
+	if s.Data.broken < ks.w.Breaks() {
+		nextMoves = append(nextMoves, ks.w.FindBreaks(from[0], from[1])...)
+	}
+
+	var moves []mole.Move[keyState]
+	for i, mov := range nextMoves {
+		to := PointOnMap{mov[0], mov[1]}
+		if to == from {
+			continue
+		}
+		next := keyState{keys: s.Data.keys, broken: s.Data.broken}
+		if next.fuel = ks.w.FuelAfter(from, to, s.Data.fuel); next.fuel < 0 {
+			continue
+		}
+		if i >= firstBreak {
+			next.broken++
+		}
+		for _, key := range ks.w.KeysOn(from, to) {
+			next.keys = next.keys.With(key)
+		}
+		rules := ks.w.Rules()
+		if ks.metric == MetricTurns {
+			next.dir = rules.NextDirection(s.Data.dir, from, to)
+		}
+		cost := rules.StepCost(ks.metric, s.Data.dir, from, to)*costScale + rules.StepCost(MetricCells, s.Data.dir, from, to)
+		moves = append(moves, mole.Move[keyState]{To: mole.State[keyState]{Point: to, Data: next}, Cost: cost})
+	}
+	return moves
+}
+
+func (ks *keySpace) IsTarget(s mole.State[keyState]) bool {
+	return s.Point == ks.exit
+}
+
+// findStateRoutes прокладывает маршруты роутером mole: по одному из каждого
+// старта к каждому выходу, с учётом ключей и дверей, сносимых стен и топлива
+func findStateRoutes(w *world.World, opts Options) []NavRoute {
+
+	exits := w.GetExitPoints()
+	targets := exits
+	if w.Movement() == MovementRook {
+		// на льду на ключе и заправке не остановиться, их проходят на ходу
+		targets = append(append(w.GetKeyPoints(), w.GetRefuelPoints()...), exits...)
+	}
+
+	var results []NavRoute
+	for _, start := range w.GetStartPoints() {
+		for _, exit := range exits {
+			space := &keySpace{w: w, metric: opts.Metric, targets: targets, exit: exit}
+			route, _, ok := mole.New[keyState](space).Build(mole.State[keyState]{Point: start, Data: keyState{fuel: w.Fuel()}})
+			if !ok {
+				continue
+			}
+			results = append(results, NavRoute{
+				RouterName:    RouterMole,
+				Route:         &route.Route,
+				RecPointLists: route.RecPointLists,
+				Cost:          route.Route.CostBy(w.Rules()),
+				Keys:          w.CollectKeys(&route.Route),
+				Broken:        w.BrokenWalls(&route.Route),
+				Fuel:          w.RouteFuel(&route.Route),
+				Start:         start,
+				Exit:          exit,
+			})
+		}
+	}
+	return results
+}
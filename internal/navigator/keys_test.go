@@ -0,0 +1,80 @@
+package navigator
+
+import (
+	"fmt"
+	"maze/internal/world"
+	"testing"
+)
+
+func TestFindRoutes_Mole(t *testing.T) {
+
+	// ключ a лежит за дверью B, выход - за дверью A
+	w, err := world.Construct(`
+		wwwwwwwwww
+		wQ A @ Baw
+		wwwww wwww
+		wb    wwww
+		wwwwwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := FindRoutes(w, RouterMole, DefaultOptions())
+	if len(routes) != 1 || !routes[0].IsFoundTarget() {
+		t.Fatalf("Failure: expected one route to exit, got %v", routes)
+	}
+	if routes[0].Keys != "ba" {
+		t.Errorf("Failure: expected keys order \"ba\", got %q", routes[0].Keys)
+	}
+	if err := world.ValidateRoute(w, routes[0].Route); err != nil {
+		t.Errorf("Failure: %v", err)
+	}
+
+	if routes := FindRoutes(w, RouterOx, DefaultOptions()); len(routes) != 0 {
+		t.Errorf("Failure: ox passed through locked doors: %v", routes)
+	}
+}
+
+func TestFindRoutes_MoleBreaks(t *testing.T) {
+
+	w, err := world.Construct(`
+		wwwwwwwwwwwww
+		w@    w    Qw
+		w wwwww wwwww
+		w           w
+		wwwwwwwwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		breaks int
+		moves  int
+		broken string
+	}{
+		{0, 4, "[]"},
+		{1, 2, "[(6,1)]"},
+		{2, 2, "[(6,1)]"}, // лишние сносы не нужны
+	}
+
+	for _, tc := range testCases {
+		t.Run("FindRoutes()", func(t *testing.T) {
+			w.SetBreaks(tc.breaks)
+			routes := FindRoutes(w, RouterMole, DefaultOptions())
+			if len(routes) != 1 || !routes[0].IsFoundTarget() {
+				t.Fatalf("Failure on %d breaks: expected one route to exit, got %v", tc.breaks, routes)
+			}
+			if moves := routes[0].Cost.Moves; moves != tc.moves {
+				t.Errorf("Failure on %d breaks: expected %d moves, got %d", tc.breaks, tc.moves, moves)
+			}
+			if broken := fmt.Sprint(routes[0].Broken); broken != tc.broken {
+				t.Errorf("Failure on %d breaks: expected broken walls %s, got %s", tc.breaks, tc.broken, broken)
+			}
+			if err := world.ValidateRoute(w, routes[0].Route); err != nil {
+				t.Errorf("Failure on %d breaks: %v", tc.breaks, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package navigator
+
+import (
+	. "maze/internal/global"
+	"maze/internal/world"
+	"slices"
+)
+
+// RouterKShortest Имя поставщика k лучших маршрутов (алгоритм Йена)
+const RouterKShortest = "k-shortest"
+
+// findKShortestRoutes возвращает до `opts.K` лучших по критерию маршрутов
+// без петель из всех стартов ко всем выходам, по возрастанию стоимости.
+// Маршруты различны: номер маршрута - номер альтернативы
+func findKShortestRoutes(w *world.World, opts Options) []NavRoute {
+
+	exits := w.GetExitPoints()
+
+	var results []NavRoute
+	for _, start := range w.GetStartPoints() {
+		graph := BuildRoutingTreeFor(w, start, exits)
+		graph.SortPointsInValues()
+		for _, exit := range exits {
+			for _, path := range kShortestPaths(w, graph, opts.Metric, start, exit, opts.K) {
+				route := Route{}
+				route.Add(path...)
+				route = w.Unfold(route)
+				results = append(results, NavRoute{
+					RouterName: RouterKShortest,
+					Route:      &route,
+					Start:      start,
+					Exit:       exit,
+					Cost:       route.CostBy(w.Rules()),
+					Keys:       w.CollectKeys(&route),
+				})
+			}
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b NavRoute) int {
+		return a.Cost.Get(opts.Metric) - b.Cost.Get(opts.Metric)
+	})
+	return results[:min(len(results), opts.K)]
+}
+
+// kShortestPaths находит до `k` кратчайших путей без петель алгоритмом Йена:
+// каждый следующий путь - лучший из ответвлений от уже найденных. Ответвление
+// повторяет начало найденного пути до точки ответвления, а дальше идёт
+// кратчайшим путём, не заходя в клетки начала и не повторяя перемещения из
+// точки ответвления, которые уже есть у найденных путей с тем же началом.
+// Петли считаются по клеткам, а не по узлам графа
+func kShortestPaths(w *world.World, graph RoutingStruct, metric Metric, start, target PointOnMap, k int) []PointList {
+
+	ks := kSearch{w: w, graph: graph, rules: w.Rules(), metric: metric}
+	first, ok := ks.path(PointList{start}, target, nil)
+	if !ok || k <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		path PointList
+		cost int
+	}
+	found := []PointList{first}
+	seen := map[string]bool{pathKey(first): true}
+	var candidates []candidate
+
+	// клетки перемещений по графу, кроме начальной
+	moveCells := map[[2]PointOnMap]PointList{}
+	cellsOf := func(from, to PointOnMap) PointList {
+		move := [2]PointOnMap{from, to}
+		if cells, ok := moveCells[move]; ok {
+			return cells
+		}
+		var cells PointList
+		for p := range w.RouteCells(routeOf(PointList{from, to})) {
+			if p != from {
+				cells = append(cells, p)
+			}
+		}
+		moveCells[move] = cells
+		return cells
+	}
+
+	for len(found) < k {
+		last := found[len(found)-1]
+		for i := 0; i < len(last)-1; i++ {
+			spur, root := last[i], last[:i+1]
+
+			rootCells := w.RouteCells(routeOf(root))
+			skip := func(from, to PointOnMap) bool {
+				for _, p := range cellsOf(from, to) {
+					if rootCells[p] {
+						return true
+					}
+				}
+				if from != spur {
+					return false
+				}
+				for _, p := range found {
+					if len(p) > i+1 && p[i+1] == to && slices.Equal(p[:i+1], root) {
+						return true
+					}
+				}
+				return false
+			}
+
+			path, ok := ks.path(root, target, skip)
+			if !ok {
+				continue
+			}
+			if key := pathKey(path); !seen[key] && w.IsLoopless(routeOf(path)) {
+				seen[key] = true
+				candidates = append(candidates, candidate{path, routeOf(path).CostBy(ks.rules).Get(metric)})
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+
+		best := 0
+		for i, c := range candidates {
+			if c.cost < candidates[best].cost {
+				best = i
+			}
+		}
+		found = append(found, candidates[best].path)
+		candidates = slices.Delete(candidates, best, best+1)
+	}
+	return found
+}
+
+// kSearch Поиск Дейкстры для ответвлений. Кроме направления для поворотов
+// состояние помнит направление прямой, по которой пришли: разворот на прямой
+// даёт петлю, а для модели rook и остановка посреди прямой с продолжением по
+// ней не даёт нового маршрута. Такие перемещения не рассматриваются
+type kSearch struct {
+	w      *world.World
+	graph  RoutingStruct
+	rules  RouteRules
+	metric Metric
+}
+
+type kState struct {
+	point PointOnMap
+	dir   Direction
+	line  Direction
+}
+
+// path возвращает кратчайший путь в точку `target`, начало которого -
+// `root`. Перемещения, для которых skip возвращает true, не рассматриваются
+func (ks kSearch) path(root PointList, target PointOnMap, skip func(from, to PointOnMap) bool) (PointList, bool) {
+
+	source := kState{point: root[0]}
+	for i := 1; i < len(root); i++ {
+		source = ks.next(source, root[i])
+	}
+
+	dist := map[kState]int{source: 0}
+	prev := map[kState]kState{}
+	queue := &PriorityQueue[kState]{}
+	queue.Push(source, 0)
+
+	for queue.Len() > 0 {
+		current, cost := queue.Pop()
+		if int(cost) > dist[current] {
+			continue
+		}
+		if current.point == target {
+			path := PointList{current.point}
+			for s, ok := prev[current]; ok; s, ok = prev[s] {
+				path = append(path, s.point)
+			}
+			slices.Reverse(path)
+			return append(slices.Clone(root[:len(root)-1]), path...), true
+		}
+		for _, nextPoint := range ks.graph[current.point] {
+			if skip != nil && skip(current.point, nextPoint) {
+				continue
+			}
+			next := ks.next(current, nextPoint)
+			if ks.isRetrace(current.line, next.line) {
+				continue
+			}
+			nextCost := dist[current] + ks.rules.StepCost(ks.metric, current.dir, current.point, nextPoint)
+			if old, ok := dist[next]; !ok || nextCost < old {
+				dist[next] = nextCost
+				prev[next] = current
+				queue.Push(next, float64(nextCost))
+			}
+		}
+	}
+	return nil, false
+}
+
+// next возвращает состояние после перемещения в точку `to`. Телепорт прямую
+// обрывает
+func (ks kSearch) next(s kState, to PointOnMap) kState {
+	next := kState{point: to}
+	if ks.metric == MetricTurns {
+		next.dir = ks.rules.NextDirection(s.dir, s.point, to)
+	}
+	if !ks.w.IsHop(s.point, to) {
+		next.line = ks.rules.NextDirection(Direction{}, s.point, to)
+	}
+	return next
+}
+
+// isRetrace проверяет, что перемещение по прямой `next` после перемещения по
+// прямой `line` разворачивает или (для модели rook) продолжает его
+func (ks kSearch) isRetrace(line, next Direction) bool {
+	if line == (Direction{}) {
+		return false
+	}
+	return next == Direction{-line[0], -line[1]} || next == line && ks.w.Movement() == MovementRook
+}
+
+// routeOf возвращает маршрут по пути графа
+func routeOf(path PointList) *Route {
+	route := &Route{}
+	route.Add(path...)
+	return route
+}
+
+func pathKey(path PointList) string {
+	return routeOf(path).Serialize()
+}
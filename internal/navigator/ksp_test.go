@@ -0,0 +1,98 @@
+package navigator
+
+import (
+	"fmt"
+	. "maze/internal/global"
+	"maze/internal/world"
+	"testing"
+)
+
+func TestFindRoutes_KShortest(t *testing.T) {
+
+	w, err := world.Construct(`
+		wwwwwww
+		w@   Qw
+		w www w
+		w     w
+		wwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		k        int
+		expected []string
+	}{
+		{1, []string{"[1 1] [5 1]"}},
+		{5, []string{"[1 1] [5 1]", "[1 1] [1 3] [5 3] [5 1]"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("k %d", tc.k), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.K = tc.k
+			routes := FindRoutes(w, RouterFox, opts)
+			if len(routes) != len(tc.expected) {
+				t.Fatalf("Failure on k %d: expected %v, got %v", tc.k, tc.expected, routes)
+			}
+			for i, route := range routes {
+				expected := (&Route{}).Unserialize(tc.expected[i])
+				if !expected.Eq(route.Route) || route.RouterName != RouterKShortest {
+					t.Errorf("Failure on k %d, #%d: expected %v, got %v", tc.k, i, *expected, route)
+				}
+			}
+		})
+	}
+}
+
+func TestFindRoutes_KShortestDistinct(t *testing.T) {
+
+	w, err := world.Construct(`
+		wwwwwwwww
+		w@      w
+		w ww ww w
+		w       w
+		w ww ww w
+		w      Qw
+		wwwwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, metric := range []Metric{MetricMoves, MetricCells, MetricTurns} {
+		t.Run(string(metric), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Metric = metric
+			opts.K = 8
+			routes := FindRoutes(w, RouterOx, opts)
+			if len(routes) != opts.K {
+				t.Fatalf("Failure on %s: expected %d routes, got %d", metric, opts.K, len(routes))
+			}
+
+			// лучший маршрут совпадает с оптимальным
+			if best, _ := FindBestRoute(w, Options{Metric: metric}); routes[0].Cost.Get(metric) != best.Cost.Get(metric) {
+				t.Errorf("Failure on %s: expected best cost %d, got %v", metric, best.Cost.Get(metric), routes[0])
+			}
+
+			seen := map[string]bool{}
+			for i, route := range routes {
+				if i > 0 && route.Cost.Get(metric) < routes[i-1].Cost.Get(metric) {
+					t.Errorf("Failure on %s, #%d: costs are not increasing", metric, i)
+				}
+				if folded := w.Fold(*route.Route); seen[folded.Serialize()] {
+					t.Errorf("Failure on %s, #%d: duplicate route %v", metric, i, route)
+				} else {
+					seen[folded.Serialize()] = true
+				}
+				if !w.IsLoopless(route.Route) {
+					t.Errorf("Failure on %s, #%d: route has a loop %v", metric, i, route)
+				}
+				if err := world.ValidateRoute(w, route.Route); err != nil {
+					t.Errorf("Failure on %s, #%d: %v", metric, i, err)
+				}
+			}
+		})
+	}
+}
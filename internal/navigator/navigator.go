@@ -7,8 +7,11 @@ import (
 	"maze/internal/navigator/routers/fox"
 	"maze/internal/navigator/routers/hare"
 	"maze/internal/navigator/routers/hog"
+	"maze/internal/navigator/routers/lynx"
+	"maze/internal/navigator/routers/ox"
 	"maze/internal/navigator/routers/wolf"
 	"maze/internal/world"
+	"slices"
 )
 
 type NavRoute struct {
@@ -30,21 +33,67 @@ type NavRoute struct {
 	// Нужно здесь для последующего воспроизведения построения и отладки.
 	RecRouteFrames []RouteFrame
 
-	target PointOnMap
+	// Cost Стоимость маршрута по всем критериям
+	Cost RouteCost
+
+	// Keys Ключи в порядке их сбора на маршруте
+	Keys string
+
+	// Broken Стены, снесённые на маршруте (только роутер mole)
+	Broken PointList
+
+	// Time Число тактов на маршрут по карте с воротами и охранниками
+	// (только роутер mole)
+	Time int
+
+	// Fuel Запас топлива в каждой точке маршрута при ограниченном топливе
+	// (только роутер mole)
+	Fuel []int
+
+	// Via Обязательные точки и предметы в порядке их обхода (только маршруты
+	// через обязательные точки и со сбором предметов)
+	Via PointList
+
+	// Start Старт, из которого прокладывался маршрут
+	Start PointOnMap
+
+	// Exit Выход, к которому прокладывался маршрут
+	Exit PointOnMap
 }
 
 func (rr NavRoute) String() string {
-	return fmt.Sprintf("%v%s", *rr.Route, rr.GetResultMarker(": "))
+	extra := ""
+	if rr.Keys != "" {
+		extra += ", keys: " + rr.Keys
+	}
+	if len(rr.Broken) > 0 {
+		extra += fmt.Sprintf(", broken: %v", rr.Broken)
+	}
+	if rr.Time > 0 {
+		extra += fmt.Sprintf(", time: %d", rr.Time)
+	}
+	if len(rr.Fuel) > 0 {
+		extra += fmt.Sprintf(", fuel left: %d", rr.Fuel[len(rr.Fuel)-1])
+	}
+	if len(rr.Via) > 0 {
+		extra += fmt.Sprintf(", via: %v", rr.Via)
+	}
+	return fmt.Sprintf("%v (%v%s)%s", *rr.Route, rr.Cost, extra, rr.GetResultMarker(": "))
 }
 
 func (rr NavRoute) IsFoundTarget() bool {
-	return rr.Route.IsFinished(rr.target)
+	return rr.Route.IsFinished(rr.Exit)
+}
+
+// ReachedExit возвращает выход, в котором закончился маршрут
+func (rr NavRoute) ReachedExit() (PointOnMap, bool) {
+	return rr.Exit, rr.IsFoundTarget()
 }
 
 func (rr NavRoute) GetResultMarker(prefixIfFound string) string {
 	foundExit := ""
-	if rr.Route.IsFinished(rr.target) {
-		foundExit = prefixIfFound + "✅ (Exit)"
+	if exit, ok := rr.ReachedExit(); ok {
+		foundExit = prefixIfFound + fmt.Sprintf("✅ (Exit %v)", exit)
 	}
 	return foundExit
 }
@@ -63,11 +112,57 @@ const (
 	RouterHog  = "hog"
 	RouterFox  = "fox"
 	RouterWolf = "wolf"
+	RouterOx   = "ox"
+	RouterLynx = "lynx"
+	RouterMole = "mole"
 )
 
-func routerFactory(name string) RouterInterface {
+// routerNames все роутеры: известные routerFactory и mole
+var routerNames = []string{
+	RouterHare,
+	RouterDeer,
+	RouterHog,
+	RouterFox,
+	RouterWolf,
+	RouterOx,
+	RouterLynx,
+	RouterMole,
+}
+
+// RouterNames возвращает имена всех роутеров
+func RouterNames() []string {
+	return append([]string{}, routerNames...)
+}
+
+// Options Настройки роутеров
+type Options struct {
+
+	// Heuristic Эвристика для роутера A* (lynx)
+	Heuristic string
+
+	// Metric Критерий оптимальности маршрута
+	Metric Metric
+
+	// K Число лучших маршрутов без петель вместо вариантов роутера (0 - не
+	// задано). Роутер mole так не работает
+	K int
+}
+
+// DefaultOptions возвращает настройки по умолчанию
+func DefaultOptions() Options {
+	return Options{
+		Heuristic: lynx.HeuristicManhattan,
+		Metric:    MetricMoves,
+	}
+}
+
+func routerFactory(name string, opts Options, w *world.World) RouterInterface {
 	var r RouterInterface
 	switch name {
+	case RouterLynx:
+		r = lynx.New(opts.Heuristic, w.Rules())
+	case RouterOx:
+		r = ox.New(opts.Metric, w.Rules())
 	case RouterWolf:
 		r = wolf.New()
 	case RouterFox:
@@ -84,68 +179,117 @@ func routerFactory(name string) RouterInterface {
 	return r
 }
 
-// FindBestRoute возвращает лучший маршрут
-func FindBestRoute(w *world.World) NavRoute {
-	return FindRoutes(w, RouterFox)[4]
+// FindBestRoute возвращает лучший маршрут по критерию из настроек. Роутер
+// mole учитывает и состояние карты (ключи, двери)
+func FindBestRoute(w *world.World, opts Options) (NavRoute, bool) {
+	routes := FindRoutes(w, RouterMole, opts)
+	if i := SelectBest(routes, opts.Metric); i != -1 {
+		return routes[i], true
+	}
+	return NavRoute{}, false
 }
 
-// FindRoutes возвращает массив маршрутов
-func FindRoutes(w *world.World, routerName string) []NavRoute {
+// SelectBest возвращает индекс самого дешёвого по критерию маршрута из тех,
+// что достигли цели, или -1, если таких нет
+func SelectBest(routes []NavRoute, metric Metric) int {
+	best := -1
+	for i, route := range routes {
+		if !route.IsFoundTarget() {
+			continue
+		}
+		if best == -1 || route.Cost.Get(metric) < routes[best].Cost.Get(metric) {
+			best = i
+		}
+	}
+	return best
+}
 
-	router := routerFactory(routerName)
-	width, height := w.GetSizes()
-	start := w.GetStart().ToArray()
-	target := w.GetExit().ToArray()
+// FindRoutes возвращает массив маршрутов. Если выходов несколько, роутер
+// прокладывает маршруты к каждому из них; остальные выходы при этом остаются
+// конечными точками дерева локаций. Если стартов несколько, маршруты
+// прокладываются из каждого. На карте с воротами и охранниками роутер mole
+// планирует по тактам. Если задано opts.K, вместо вариантов роутера
+// возвращаются k лучших маршрутов (см. findKShortestRoutes)
+func FindRoutes(w *world.World, routerName string, opts Options) []NavRoute {
 
-	rsConstructor := func(reverted bool) RoutingStruct {
-		a, b := start, target
-		if reverted {
-			a, b = b, a
-		}
-		rs := BuildRoutingTreeFor(w, a, b)
-		rs.SortPointsInValues()
-		return rs
+	if routerName == RouterMole && w.IsTimed() {
+		return findTimedRoutes(w)
+	}
+	if routerName == RouterMole {
+		return findStateRoutes(w, opts)
+	}
+	if opts.K > 0 {
+		return findKShortestRoutes(w, opts)
 	}
 
-	allRoutes := router.BuildRoutes(rsConstructor, start, target, width, height)
+	var results []NavRoute
+	for _, start := range w.GetStartPoints() {
+		results = append(results, findRoutesFrom(w, routerName, opts, start)...)
+	}
+	return results
+}
 
-	//// сверху короткие маршруты
-	//sort.Slice(allRoutes, func(i, j int) bool {
-	//	return allRoutes[i].Route.GetLength() < allRoutes[j].Route.GetLength()
-	//})
+func findRoutesFrom(w *world.World, routerName string, opts Options, start PointOnMap) []NavRoute {
+
+	router := routerFactory(routerName, opts, w)
+	width, height := w.GetSizes()
+	exits := w.GetExitPoints()
 
 	var results []NavRoute
-	for _, route := range allRoutes {
-		results = append(results, NavRoute{
-			target:         target,
-			Route:          &route.Route,
-			RecRouteFrames: route.RecRouteFrames,
-			RecPointLists:  route.RecPointLists,
-			RouterName:     routerName,
-		})
+	for _, target := range exits {
+
+		rsConstructor := func(reverted bool) RoutingStruct {
+			var rs RoutingStruct
+			if reverted {
+				// обратное дерево - прямое с развёрнутыми переходами
+				rs = ReverseRoutingStruct(BuildRoutingTreeFor(w, start, exits))
+				rs[start] = PointList{}
+			} else {
+				rs = BuildRoutingTreeFor(w, start, exits)
+			}
+			rs.SortPointsInValues()
+			return rs
+		}
+
+		allRoutes := router.BuildRoutes(rsConstructor, start, target, width, height)
+
+		for _, route := range allRoutes {
+			route.Route = w.Unfold(route.Route)
+			results = append(results, NavRoute{
+				Start:          start,
+				Exit:           target,
+				Route:          &route.Route,
+				RecRouteFrames: route.RecRouteFrames,
+				RecPointLists:  route.RecPointLists,
+				RouterName:     routerName,
+				Cost:           route.Route.CostBy(w.Rules()),
+				Keys:           w.CollectKeys(&route.Route),
+			})
+		}
 	}
 	return results
 }
 
 // BuildRoutingTreeFor выполняет обход в ширину и возвращает дерево локаций
-// для прокладывания маршрутов
-func BuildRoutingTreeFor(w *world.World, start, target PointOnMap) RoutingStruct {
+// для прокладывания маршрутов. Цели - конечные точки дерева
+func BuildRoutingTreeFor(w *world.World, start PointOnMap, targets PointList) RoutingStruct {
 
-	tree := RoutingStruct{target: PointList{}}
-	queue := append(PointList{}, start)
+	tree := RoutingStruct{}
 	queueRegistry := PointRegistry{start: true}
+	for _, target := range targets {
+		tree[target] = PointList{}
+		queueRegistry[target] = true
+	}
+	queue := append(PointList{}, start)
 	pointReg := PointRegistry{}
 
 	for i := 0; i < len(queue); i++ {
 		point := queue[i]
-		for _, mov := range w.FindNextMoves(point[0], point[1], target[0], target[1]) {
+		for _, mov := range w.FindNextMoves(point[0], point[1], targets) {
 			nextPoint := PointOnMap{mov[0], mov[1]}
 			pointReg[nextPoint] = true
 			if _, exist := queueRegistry[nextPoint]; !exist {
 				//pointReg[nextPoint] = true
-				if target == nextPoint {
-					continue
-				}
 				queue = append(queue, nextPoint)
 				queueRegistry[nextPoint] = true
 			}
@@ -164,14 +308,49 @@ func BuildRoutingTreeFor(w *world.World, start, target PointOnMap) RoutingStruct
 	return tree
 }
 
+// ReverseRoutingStruct разворачивает все переходы дерева локаций
+func ReverseRoutingStruct(rs RoutingStruct) RoutingStruct {
+	reversed := RoutingStruct{}
+	for from, points := range rs {
+		for _, to := range points {
+			reversed[to] = append(reversed[to], from)
+		}
+	}
+	reversed.SortPointsInValues()
+	return reversed
+}
+
 func BuildRoutingTree(w *world.World) RoutingStruct {
 	start := w.GetStart().ToArray()
-	target := w.GetExit().ToArray()
-	rs := BuildRoutingTreeFor(w, start, target)
+	rs := BuildRoutingTreeFor(w, start, w.GetExitPoints())
 	rs.SortPointsInValues() // reach stable routes
 	return rs
 }
 
+// IsReachable проверяет, что цель достижима из старта по дереву локаций
+func IsReachable(rs RoutingStruct, start, target PointOnMap) bool {
+	return IsAnyReachable(rs, start, PointList{target})
+}
+
+// IsAnyReachable проверяет, что из старта достижима хотя бы одна из целей
+func IsAnyReachable(rs RoutingStruct, start PointOnMap, targets PointList) bool {
+	queue := PointList{start}
+	visited := PointRegistry{start: true}
+	for i := 0; i < len(queue); i++ {
+		point := queue[i]
+		if slices.Contains(targets, point) {
+			return true
+		}
+		for _, nextPoint := range rs[point] {
+			if !visited[nextPoint] {
+				visited[nextPoint] = true
+				queue = append(queue, nextPoint)
+			}
+		}
+	}
+	return false
+}
+
 func PrintRoutingTree(rs RoutingStruct) {
 	keys := rs.ToKeys()
 	keys.Sort()
@@ -0,0 +1,78 @@
+package navigator
+
+import (
+	. "maze/internal/global"
+)
+
+// pathState Состояние поиска кратчайших путей по графу локаций: точка и
+// направление прихода (только для поворотов)
+type pathState struct {
+	point PointOnMap
+	dir   Direction
+}
+
+// pathTree Кратчайшие пути от источников: стоимость и предшественник каждого
+// состояния, а для точки - первое (лучшее) состояние в ней
+type pathTree struct {
+	dist    map[pathState]int
+	prev    map[pathState]pathState
+	settled map[PointOnMap]pathState
+}
+
+// shortestPaths выполняет поиск Дейкстры по графу локаций сразу от всех
+// источников, стоимость перемещений - по критерию и правилам карты
+func shortestPaths(graph RoutingStruct, rules RouteRules, metric Metric, sources PointList) pathTree {
+
+	pt := pathTree{
+		dist:    map[pathState]int{},
+		prev:    map[pathState]pathState{},
+		settled: map[PointOnMap]pathState{},
+	}
+	queue := &PriorityQueue[pathState]{}
+	for _, source := range sources {
+		s := pathState{point: source}
+		pt.dist[s] = 0
+		queue.Push(s, 0)
+	}
+
+	for queue.Len() > 0 {
+		current, cost := queue.Pop()
+		if int(cost) > pt.dist[current] {
+			continue
+		}
+		if _, ok := pt.settled[current.point]; !ok {
+			pt.settled[current.point] = current
+		}
+		for _, nextPoint := range graph[current.point] {
+			next := pathState{point: nextPoint}
+			if metric == MetricTurns {
+				next.dir = rules.NextDirection(current.dir, current.point, nextPoint)
+			}
+			nextCost := pt.dist[current] + rules.StepCost(metric, current.dir, current.point, nextPoint)
+			if old, ok := pt.dist[next]; !ok || nextCost < old {
+				pt.dist[next] = nextCost
+				pt.prev[next] = current
+				queue.Push(next, float64(nextCost))
+			}
+		}
+	}
+	return pt
+}
+
+// cost возвращает стоимость кратчайшего пути до точки
+func (pt pathTree) cost(p PointOnMap) (int, bool) {
+	s, ok := pt.settled[p]
+	return pt.dist[s], ok
+}
+
+// chain возвращает цепочку предшественников от точки до источника
+// (только точку, если она не достигнута)
+func (pt pathTree) chain(p PointOnMap) PointList {
+	chain := PointList{p}
+	for s, ok := pt.settled[p]; ok; {
+		if s, ok = pt.prev[s]; ok {
+			chain = append(chain, s.point)
+		}
+	}
+	return chain
+}
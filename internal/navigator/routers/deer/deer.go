@@ -38,9 +38,9 @@ func (r ThisRouter) BuildRoutes(
 
 	allResults := []RouterResult{
 		{
-			rp.doRoute(Route{}, rp.start),
-			rp.recPointLists,
-			nil,
+			Route:          rp.doRoute(Route{}, rp.start),
+			RecPointLists:  rp.recPointLists,
+			RecRouteFrames: nil,
 		},
 	}
 	return allResults
@@ -40,9 +40,9 @@ func (r ThisRouter) BuildRoutes(
 
 	allResults := []RouterResult{
 		{
-			rp.doRoute(Route{}, rp.start),
-			rp.recPointLists,
-			rp.recRouteFrames,
+			Route:          rp.doRoute(Route{}, rp.start),
+			RecPointLists:  rp.recPointLists,
+			RecRouteFrames: rp.recRouteFrames,
 		},
 	}
 	return allResults
@@ -73,7 +73,9 @@ func (rp *plan) findNextPoint(route Route, points PointList) (*PointOnMap, bool)
 	frmMinPoint, frmMaxPoint := rp.lastFrame()
 	minX, minY := frmMinPoint[0], frmMinPoint[1]
 	maxX, maxY := frmMaxPoint[0], frmMaxPoint[1]
-	for _, point := range points {
+	// фреймы сужаются только по точкам на одной вертикали или горизонтали,
+	// а шаг по диагонали (king) их не сужает - пройденные точки пропускаем
+	for _, point := range points.FilterWhereNotIn(route) {
 		x, y := point[0], point[1]
 		if minX < x && x < maxX && minY < y && y < maxY {
 			pointsInFrame = append(pointsInFrame, point)
@@ -0,0 +1,37 @@
+package hare
+
+import (
+	. "maze/internal/global"
+	"testing"
+	"time"
+)
+
+func TestBuildRoutes_King(t *testing.T) {
+
+	// шаги по диагонали не сужают фреймы: без учёта пройденных точек заяц
+	// ходит между [0 0] и [1 1] без конца
+	graph := RoutingStruct{
+		{0, 0}: {{1, 1}},
+		{1, 1}: {{0, 0}, {2, 0}},
+		{2, 0}: {},
+	}
+	rsProvider := func(reverted bool) RoutingStruct {
+		return graph
+	}
+
+	done := make(chan []RouterResult)
+	go func() {
+		done <- New().BuildRoutes(rsProvider, PointOnMap{0, 0}, PointOnMap{0, 3}, 3, 4)
+	}()
+
+	select {
+	case results := <-done:
+		expected := (&Route{}).Unserialize("[0 0] [1 1] [2 0]")
+		if result := results[0].Route; !expected.Eq(&result) {
+			f := "Failure (EXPECT ≠ RESULT):\nEXPECT: %v\nRESULT: %v"
+			t.Errorf(f, *expected, result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Failure: hare does not stop on diagonal moves")
+	}
+}
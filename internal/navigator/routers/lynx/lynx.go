@@ -0,0 +1,285 @@
+package lynx
+
+import (
+	"fmt"
+	"math"
+	. "maze/internal/global"
+	"slices"
+)
+
+const (
+	HeuristicManhattan = "manhattan"
+	HeuristicEuclidean = "euclid"
+	HeuristicTurns     = "turns"
+)
+
+// Heuristics все эвристики
+var Heuristics = []string{HeuristicManhattan, HeuristicEuclidean, HeuristicTurns}
+
+// ParseHeuristic проверяет имя эвристики
+func ParseHeuristic(name string) (string, error) {
+	if slices.Contains(Heuristics, name) {
+		return name, nil
+	}
+	return "", fmt.Errorf("unknown heuristic %q, expected one of %v", name, Heuristics)
+}
+
+// heuristic оценивает снизу число клеток, которые осталось пройти до цели
+type heuristic func(from, target PointOnMap) float64
+
+// manhattan расстояние городских кварталов: точное для пустого поля
+func manhattan(from, target PointOnMap) float64 {
+	return float64(abs(target[0]-from[0]) + abs(target[1]-from[1]))
+}
+
+// chebyshev расстояние для модели king: шаг по диагонали - одна клетка
+func chebyshev(from, target PointOnMap) float64 {
+	return float64(max(abs(target[0]-from[0]), abs(target[1]-from[1])))
+}
+
+// euclid расстояние по прямой
+func euclid(from, target PointOnMap) float64 {
+	return from.CalcDistance(target)
+}
+
+// turns наименьшее число оставшихся перемещений: ноль в цели, одно перемещение
+// если цель на одной прямой (без поворотов) и два в остальных случаях.
+// Каждое перемещение проходит хотя бы одну клетку, поэтому оценка допустима
+func turns(from, target PointOnMap) float64 {
+	switch {
+	case from == target:
+		return 0
+	case from[0] == target[0] || from[1] == target[1]:
+		return 1
+	}
+	return 2
+}
+
+// kingTurns то же для модели king: прямые идут и по диагоналям
+func kingTurns(from, target PointOnMap) float64 {
+	if dx, dy := abs(target[0]-from[0]), abs(target[1]-from[1]); dx == dy && dx > 0 {
+		return 1
+	}
+	return turns(from, target)
+}
+
+// hexEuclid расстояние по прямой между центрами шестиугольных клеток,
+// соседние центры - на расстоянии единицы
+func hexEuclid(from, target PointOnMap) float64 {
+	a, b := ToAxial(from), ToAxial(target)
+	dq, dr := float64(b[0]-a[0]), float64(b[1]-a[1])
+	return math.Hypot(dq+dr/2, dr*math.Sqrt(3)/2)
+}
+
+// hexTurns то же, что turns, для прямых шестиугольной сетки
+func hexTurns(from, target PointOnMap) float64 {
+	switch _, ok := HexDirectionOf(from, target); {
+	case from == target:
+		return 0
+	case ok:
+		return 1
+	}
+	return 2
+}
+
+// wrapped то же, что `h`, при сквозных краях: до цели можно дойти и через
+// край, поэтому по каждой оси берётся ближайшая к точке копия цели, сдвинутая
+// на ширину карты (высоту этажа)
+func wrapped(h heuristic, size func(p PointOnMap) (int, int)) heuristic {
+	return func(from, target PointOnMap) float64 {
+		width, height := size(from)
+		return h(from, PointOnMap{nearest(from[0], target[0], width), nearest(from[1], target[1], height)})
+	}
+}
+
+// nearest возвращает ближайшую к `a` из координат `b`, `b-size` и `b+size`
+func nearest(a, b, size int) int {
+	for _, c := range []int{b - size, b + size} {
+		if abs(c-a) < abs(b-a) {
+			b = c
+		}
+	}
+	return b
+}
+
+// heuristicFactory возвращает эвристику для правил карты. Для модели king
+// шаг по диагонали стоит одну клетку, поэтому оценки уменьшены, чтобы
+// оставаться допустимыми. На шестиугольной сетке расстояния и прямые
+// считаются по её клеткам, при сквозных краях - с учётом перемещений через край
+func heuristicFactory(name string, rules RouteRules) heuristic {
+	king := rules.Movement == MovementKing
+	var h heuristic
+	switch {
+	case name == HeuristicManhattan && rules.Hex:
+		h = func(from, target PointOnMap) float64 {
+			return float64(HexDistance(from, target))
+		}
+	case name == HeuristicEuclidean && rules.Hex:
+		h = hexEuclid
+	case name == HeuristicTurns && rules.Hex:
+		h = hexTurns
+	case name == HeuristicManhattan && king:
+		h = chebyshev
+	case name == HeuristicManhattan:
+		h = manhattan
+	case name == HeuristicEuclidean && king:
+		h = func(from, target PointOnMap) float64 {
+			return euclid(from, target) / math.Sqrt2
+		}
+	case name == HeuristicEuclidean:
+		h = euclid
+	case name == HeuristicTurns && king:
+		h = kingTurns
+	case name == HeuristicTurns:
+		h = turns
+	default:
+		panic(fmt.Sprintf("Unknown heuristic: %s", name))
+	}
+	if rules.WrapSize != nil && !rules.Hex {
+		h = wrapped(h, rules.WrapSize)
+	}
+	return h
+}
+
+type ThisRouter struct {
+	heuristic heuristic
+	rules     RouteRules
+	plan      *plan
+}
+
+func New(heuristicName string, rules RouteRules) *ThisRouter {
+	return &ThisRouter{
+		heuristic: heuristicFactory(heuristicName, rules),
+		rules:     rules,
+	}
+}
+
+func (tr *ThisRouter) createResult(route Route) RouterResult {
+	return RouterResult{
+		Route:          route,
+		RecPointLists:  tr.plan.recPointLists,
+		RecRouteFrames: nil,
+	}
+}
+
+type plan struct {
+	start  PointOnMap
+	target PointOnMap
+	graph  RoutingStruct
+
+	// hopEntries Телепорты, из которых можно прыгнуть
+	hopEntries PointList
+	// hopBound Оценка пути от ближайшего к цели телепорта, в который прыгаем
+	hopBound float64
+
+	// recPointLists Фиксируем множества точек для каждого раскрытого узла
+	recPointLists []PointList
+}
+
+func (tr *ThisRouter) BuildRoutes(
+	rsProvider func(reverted bool) RoutingStruct,
+	start, target PointOnMap,
+	width, height int,
+) []RouterResult {
+
+	tr.plan = &plan{
+		start:  start,
+		target: target,
+		graph:  rsProvider(false),
+	}
+	tr.findHops()
+
+	var allResults []RouterResult
+	if route, ok := tr.build(); ok {
+		allResults = append(allResults, tr.createResult(route))
+	}
+	return allResults
+}
+
+// build выполняет поиск A* по числу пройденных клеток
+func (tr *ThisRouter) build() (Route, bool) {
+
+	rp := tr.plan
+	dist := map[PointOnMap]int{rp.start: 0}
+	prev := map[PointOnMap]PointOnMap{}
+	done := PointRegistry{}
+
+	queue := &PriorityQueue[PointOnMap]{}
+	queue.Push(rp.start, tr.estimate(rp.start))
+
+	for queue.Len() > 0 {
+		point, _ := queue.Pop()
+		if done[point] {
+			continue
+		}
+		done[point] = true
+
+		if point == rp.target {
+			return rp.restore(prev), true
+		}
+
+		nextPoints := rp.graph[point]
+		rp.recPointLists = append(rp.recPointLists, nextPoints)
+
+		for _, nextPoint := range nextPoints {
+			if done[nextPoint] {
+				continue
+			}
+			d := dist[point] + tr.rules.StepCost(MetricCells, Direction{}, point, nextPoint)
+			if known, ok := dist[nextPoint]; !ok || d < known {
+				dist[nextPoint] = d
+				prev[nextPoint] = point
+				queue.Push(nextPoint, float64(d)+tr.estimate(nextPoint))
+			}
+		}
+	}
+	return Route{}, false
+}
+
+// findHops находит в графе прыжки через телепорты
+func (tr *ThisRouter) findHops() {
+	rp := tr.plan
+	rp.hopBound = math.Inf(1)
+	if tr.rules.IsHop == nil {
+		return
+	}
+	for point, nextPoints := range rp.graph {
+		for _, nextPoint := range nextPoints {
+			if tr.rules.IsHop(point, nextPoint) {
+				rp.hopEntries = append(rp.hopEntries, point)
+				rp.hopBound = min(rp.hopBound, tr.heuristic(nextPoint, rp.target))
+			}
+		}
+	}
+}
+
+// estimate оценивает снизу число клеток от точки до цели. Прыжок через
+// телепорт бесплатен, поэтому путь с прыжками не короче пути до ближайшего
+// телепорта и пути от ближайшего к цели телепорта, в который прыгаем
+func (tr *ThisRouter) estimate(point PointOnMap) float64 {
+	rp := tr.plan
+	h := tr.heuristic(point, rp.target)
+	for _, entry := range rp.hopEntries {
+		h = min(h, tr.heuristic(point, entry)+rp.hopBound)
+	}
+	return h
+}
+
+// restore собирает маршрут от цели к старту по ссылкам на предыдущие узлы
+func (rp *plan) restore(prev map[PointOnMap]PointOnMap) Route {
+	route := Route{}
+	for point := rp.target; ; point = prev[point] {
+		route.Add(point)
+		if point == rp.start {
+			break
+		}
+	}
+	return route.Reverse()
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
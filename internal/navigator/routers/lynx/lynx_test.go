@@ -0,0 +1,198 @@
+package lynx
+
+import (
+	. "maze/internal/global"
+	"testing"
+)
+
+func TestBuildRoutes(t *testing.T) {
+
+	start, target := PointOnMap{0, 0}, PointOnMap{5, 5}
+
+	graph := RoutingStruct{
+		{0, 0}:  {{0, 50}, {1, 0}},
+		{0, 50}: {{0, 0}, {5, 50}},
+		{5, 50}: {{0, 50}, {5, 5}},
+		{1, 0}:  {{0, 0}, {1, 1}},
+		{1, 1}:  {{1, 0}, {2, 1}},
+		{2, 1}:  {{1, 1}, {2, 2}},
+		{2, 2}:  {{2, 1}, {5, 2}},
+		{5, 2}:  {{2, 2}, {5, 5}},
+		{5, 5}:  {},
+	}
+	rsProvider := func(reverted bool) RoutingStruct {
+		return graph
+	}
+
+	expected := (&Route{}).Unserialize("[0 0] [1 0] [1 1] [2 1] [2 2] [5 2] [5 5]")
+
+	for _, name := range []string{HeuristicManhattan, HeuristicEuclidean, HeuristicTurns} {
+		t.Run("BuildRoutes("+name+")", func(t *testing.T) {
+			results := New(name, RouteRules{}).BuildRoutes(rsProvider, start, target, 6, 51)
+			if len(results) != 1 {
+				t.Fatalf("Failure: expected 1 route, got %d", len(results))
+			}
+			if result := results[0].Route; !expected.Eq(&result) {
+				f := "Failure (EXPECT ≠ RESULT):\nEXPECT: %v\nRESULT: %v"
+				t.Errorf(f, *expected, result)
+			}
+		})
+	}
+}
+
+func TestParseHeuristic(t *testing.T) {
+	for _, name := range Heuristics {
+		if h, err := ParseHeuristic(name); err != nil || h != name {
+			t.Errorf("Failure on %s: %v", name, err)
+		}
+	}
+	if _, err := ParseHeuristic("foo"); err == nil {
+		t.Errorf("Failure on foo: expected error")
+	}
+}
+
+func TestBuildRoutes_King(t *testing.T) {
+
+	// зигзаг по диагоналям - три клетки, прямой путь - четыре
+	start, target := PointOnMap{0, 0}, PointOnMap{3, 1}
+	graph := RoutingStruct{
+		{0, 0}: {{0, 1}, {1, 1}},
+		{0, 1}: {{3, 1}},
+		{1, 1}: {{2, 0}},
+		{2, 0}: {{3, 1}},
+		{3, 1}: {},
+	}
+	rsProvider := func(reverted bool) RoutingStruct {
+		return graph
+	}
+
+	expected := (&Route{}).Unserialize("[0 0] [1 1] [2 0] [3 1]")
+	rules := RouteRules{Movement: MovementKing}
+
+	for _, name := range Heuristics {
+		t.Run("BuildRoutes("+name+")", func(t *testing.T) {
+			results := New(name, rules).BuildRoutes(rsProvider, start, target, 4, 2)
+			if len(results) != 1 {
+				t.Fatalf("Failure: expected 1 route, got %d", len(results))
+			}
+			if result := results[0].Route; !expected.Eq(&result) {
+				f := "Failure (EXPECT ≠ RESULT):\nEXPECT: %v\nRESULT: %v"
+				t.Errorf(f, *expected, result)
+			}
+		})
+	}
+}
+
+func TestHeuristicAdmissible(t *testing.T) {
+
+	// на пустом поле оценка не превышает числа клеток до цели
+	testCases := []struct {
+		name  string
+		rules RouteRules
+		cells func(from, to PointOnMap) int
+	}{
+		{"rook", RouteRules{}, func(from, to PointOnMap) int {
+			return abs(to[0]-from[0]) + abs(to[1]-from[1])
+		}},
+		{"king", RouteRules{Movement: MovementKing}, func(from, to PointOnMap) int {
+			return max(abs(to[0]-from[0]), abs(to[1]-from[1]))
+		}},
+		{"hex", RouteRules{Hex: true}, HexDistance},
+	}
+
+	target := PointOnMap{4, 4}
+	for _, tc := range testCases {
+		for _, name := range Heuristics {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				h := heuristicFactory(name, tc.rules)
+				for x := 0; x < 9; x++ {
+					for y := 0; y < 9; y++ {
+						from := PointOnMap{x, y}
+						if d := tc.cells(from, target); h(from, target) > float64(d)+1e-9 {
+							t.Errorf("Failure on %v: estimate %.2f exceeds %d cells", from, h(from, target), d)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestBuildRoutes_Portals(t *testing.T) {
+
+	// коридор от [1 0] до [18 0], телепорт [1 0] ведёт в [17 0]: прыжок
+	// бесплатен, и путь через него короче, чем по коридору
+	start, target := PointOnMap{2, 0}, PointOnMap{18, 0}
+	portal, twin := PointOnMap{1, 0}, PointOnMap{17, 0}
+
+	graph := RoutingStruct{}
+	for x := 1; x <= 18; x++ {
+		p := PointOnMap{x, 0}
+		if x > 1 {
+			graph[p] = append(graph[p], PointOnMap{x - 1, 0})
+		}
+		if x < 18 {
+			graph[p] = append(graph[p], PointOnMap{x + 1, 0})
+		}
+	}
+	graph[portal] = append(graph[portal], twin)
+	graph[twin] = append(graph[twin], portal)
+	rsProvider := func(reverted bool) RoutingStruct {
+		return graph
+	}
+	rules := RouteRules{IsHop: func(from, to PointOnMap) bool {
+		return from == portal && to == twin || from == twin && to == portal
+	}}
+
+	expected := (&Route{}).Unserialize("[2 0] [1 0] [17 0] [18 0]")
+	for _, name := range Heuristics {
+		t.Run("BuildRoutes("+name+")", func(t *testing.T) {
+			results := New(name, rules).BuildRoutes(rsProvider, start, target, 19, 1)
+			if len(results) != 1 {
+				t.Fatalf("Failure: expected 1 route, got %d", len(results))
+			}
+			if result := results[0].Route; !expected.Eq(&result) {
+				f := "Failure (EXPECT ≠ RESULT):\nEXPECT: %v\nRESULT: %v"
+				t.Errorf(f, *expected, result)
+			}
+		})
+	}
+}
+
+func TestBuildRoutes_Wrap(t *testing.T) {
+
+	// строка из десяти клеток со сквозными краями: до цели три клетки через
+	// край и семь напрямую
+	start, target := PointOnMap{1, 0}, PointOnMap{8, 0}
+
+	graph := RoutingStruct{}
+	for x := 0; x < 10; x++ {
+		p := PointOnMap{x, 0}
+		graph[p] = PointList{{(x + 9) % 10, 0}, {(x + 1) % 10, 0}}
+	}
+	rsProvider := func(reverted bool) RoutingStruct {
+		return graph
+	}
+	rules := RouteRules{
+		Cells: func(from, to PointOnMap) int {
+			return min(abs(to[0]-from[0]), 10-abs(to[0]-from[0]))
+		},
+		WrapSize: func(p PointOnMap) (int, int) {
+			return 10, 1
+		},
+	}
+
+	expected := (&Route{}).Unserialize("[1 0] [0 0] [9 0] [8 0]")
+	for _, name := range Heuristics {
+		t.Run("BuildRoutes("+name+")", func(t *testing.T) {
+			results := New(name, rules).BuildRoutes(rsProvider, start, target, 10, 1)
+			if len(results) != 1 {
+				t.Fatalf("Failure: expected 1 route, got %d", len(results))
+			}
+			if result := results[0].Route; !expected.Eq(&result) {
+				f := "Failure (EXPECT ≠ RESULT):\nEXPECT: %v\nRESULT: %v"
+				t.Errorf(f, *expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package mole
+
+import (
+	. "maze/internal/global"
+)
+
+// State Состояние поиска: точка на карте и дополнительные данные, без
+// которых по одной точке нельзя решить, куда можно идти дальше (собранные
+// ключи, направление для подсчёта поворотов и т.п.)
+type State[T comparable] struct {
+	Point PointOnMap
+	Data  T
+}
+
+// Move Переход в соседнее состояние
+type Move[T comparable] struct {
+	To   State[T]
+	Cost int
+}
+
+// Space Пространство состояний
+type Space[T comparable] interface {
+
+	// Next возвращает переходы из состояния
+	Next(s State[T]) []Move[T]
+
+	// IsTarget проверяет, что состояние - цель
+	IsTarget(s State[T]) bool
+}
+
+// ThisRouter Роутер по пространству состояний. В отличие от остальных
+// роутеров работает не с деревом локаций, а с переходами, которые
+// пространство состояний строит на лету
+type ThisRouter[T comparable] struct {
+	space Space[T]
+	plan  *plan[T]
+}
+
+func New[T comparable](space Space[T]) *ThisRouter[T] {
+	return &ThisRouter[T]{space: space}
+}
+
+type plan[T comparable] struct {
+	start State[T]
+
+	// recPointLists Фиксируем множества точек для каждого раскрытого состояния
+	recPointLists []PointList
+}
+
+// Build выполняет поиск Дейкстры от старта до первого состояния-цели и
+// возвращает маршрут вместе с цепочкой пройденных состояний
+func (tr *ThisRouter[T]) Build(start State[T]) (RouterResult, []State[T], bool) {
+
+	tr.plan = &plan[T]{start: start}
+	rp := tr.plan
+
+	dist := map[State[T]]int{start: 0}
+	prev := map[State[T]]State[T]{}
+	done := map[State[T]]bool{}
+
+	queue := &PriorityQueue[State[T]]{}
+	queue.Push(start, 0)
+
+	for queue.Len() > 0 {
+		current, _ := queue.Pop()
+		if done[current] {
+			continue
+		}
+		done[current] = true
+
+		if tr.space.IsTarget(current) {
+			states := rp.restore(prev, current)
+			return tr.createResult(states), states, true
+		}
+
+		moves := tr.space.Next(current)
+		nextPoints := make(PointList, 0, len(moves))
+		for _, mov := range moves {
+			nextPoints = append(nextPoints, mov.To.Point)
+			if done[mov.To] {
+				continue
+			}
+			d := dist[current] + mov.Cost
+			if known, ok := dist[mov.To]; !ok || d < known {
+				dist[mov.To] = d
+				prev[mov.To] = current
+				queue.Push(mov.To, float64(d))
+			}
+		}
+		rp.recPointLists = append(rp.recPointLists, nextPoints)
+	}
+	return tr.createResult(nil), nil, false
+}
+
+func (tr *ThisRouter[T]) createResult(states []State[T]) RouterResult {
+	route := Route{}
+	for _, s := range states {
+		route.Add(s.Point)
+	}
+	return RouterResult{
+		Route:          route,
+		RecPointLists:  tr.plan.recPointLists,
+		RecRouteFrames: nil,
+	}
+}
+
+// restore собирает цепочку состояний от цели к старту по ссылкам на предыдущие
+func (rp *plan[T]) restore(prev map[State[T]]State[T], last State[T]) []State[T] {
+	var states []State[T]
+	for current := last; ; current = prev[current] {
+		states = append(states, current)
+		if current == rp.start {
+			break
+		}
+	}
+	for i, j := 0, len(states)-1; i < j; i, j = i+1, j-1 {
+		states[i], states[j] = states[j], states[i]
+	}
+	return states
+}
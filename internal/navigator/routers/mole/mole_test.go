@@ -0,0 +1,58 @@
+package mole
+
+import (
+	. "maze/internal/global"
+	"testing"
+)
+
+// lineSpace Точки на прямой от 0 до 5, дверь в точке 3 открывает ключ
+// в точке 1
+type lineSpace struct{}
+
+func (lineSpace) Next(s State[bool]) []Move[bool] {
+	var moves []Move[bool]
+	for _, dx := range []int{-1, 1} {
+		x := s.Point[0] + dx
+		if x < 0 || x > 5 || (x == 3 && !s.Data) {
+			continue
+		}
+		moves = append(moves, Move[bool]{To: State[bool]{Point: PointOnMap{x, 0}, Data: s.Data || x == 1}, Cost: 1})
+	}
+	return moves
+}
+
+func (lineSpace) IsTarget(s State[bool]) bool {
+	return s.Point == PointOnMap{5, 0}
+}
+
+func TestBuild(t *testing.T) {
+
+	expected := (&Route{}).Unserialize("[2 0] [1 0] [2 0] [3 0] [4 0] [5 0]")
+
+	result, states, ok := New[bool](lineSpace{}).Build(State[bool]{Point: PointOnMap{2, 0}})
+	if !ok {
+		t.Fatal("Failure: route not found")
+	}
+	if !expected.Eq(&result.Route) {
+		f := "Failure, EXPECT ≠ RESULT):\nEXPECT: %v\nRESULT: %v"
+		t.Errorf(f, *expected, result.Route)
+	}
+	if len(states) != expected.GetLength() || states[0].Data || !states[len(states)-1].Data {
+		t.Errorf("Failure: bad states %v", states)
+	}
+}
+
+func TestBuild_NoRoute(t *testing.T) {
+
+	start := State[bool]{Point: PointOnMap{0, 0}}
+	if _, _, ok := New[bool](noExit{}).Build(start); ok {
+		t.Errorf("Failure: route found without exit")
+	}
+}
+
+// noExit Та же прямая, но без цели
+type noExit struct{ lineSpace }
+
+func (noExit) IsTarget(State[bool]) bool {
+	return false
+}
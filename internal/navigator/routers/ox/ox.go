@@ -0,0 +1,155 @@
+package ox
+
+import (
+	. "maze/internal/global"
+)
+
+// costScale Множитель основного критерия: при равной основной стоимости
+// выбирается маршрут с меньшей дополнительной
+const costScale = 1 << 20
+
+type ThisRouter struct {
+	metric Metric
+	rules  RouteRules
+	plan   *plan
+}
+
+func New(metric Metric, rules RouteRules) *ThisRouter {
+	return &ThisRouter{metric: metric, rules: rules}
+}
+
+func (tr *ThisRouter) createResult(route Route) RouterResult {
+	return RouterResult{
+		Route:          route,
+		RecPointLists:  tr.plan.recPointLists,
+		RecRouteFrames: nil,
+	}
+}
+
+type plan struct {
+	start     PointOnMap
+	target    PointOnMap
+	graph     RoutingStruct
+	metric    Metric
+	secondary Metric
+	rules     RouteRules
+
+	// recPointLists Фиксируем множества точек для каждого раскрытого узла
+	recPointLists []PointList
+}
+
+// state Узел графа вместе с направлением, которым в него пришли. Направление
+// нужно только для подсчёта поворотов
+type state struct {
+	point PointOnMap
+	dir   Direction
+}
+
+func (tr *ThisRouter) BuildRoutes(
+	rsProvider func(reverted bool) RoutingStruct,
+	start, target PointOnMap,
+	width, height int,
+) []RouterResult {
+
+	var allResults []RouterResult
+	graph := rsProvider(false)
+
+	// #0 оптимальный маршрут по выбранному критерию, далее по остальным
+	for _, metric := range tr.metricsOrder() {
+		secondary := MetricCells
+		if metric == MetricCells {
+			secondary = MetricMoves
+		}
+		tr.plan = &plan{
+			start:     start,
+			target:    target,
+			graph:     graph,
+			metric:    metric,
+			secondary: secondary,
+			rules:     tr.rules,
+		}
+		if route, ok := tr.build(); ok {
+			allResults = append(allResults, tr.createResult(route))
+		}
+	}
+
+	return allResults
+}
+
+func (tr *ThisRouter) metricsOrder() []Metric {
+	metrics := []Metric{tr.metric}
+	for _, m := range Metrics {
+		if m != tr.metric {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics
+}
+
+// build выполняет поиск Дейкстры от старта до цели. Для числа перемещений это
+// по сути поиск в ширину
+func (tr *ThisRouter) build() (Route, bool) {
+
+	rp := tr.plan
+	first := state{point: rp.start}
+	dist := map[state]int{first: 0}
+	prev := map[state]state{}
+	done := map[state]bool{}
+
+	queue := &PriorityQueue[state]{}
+	queue.Push(first, 0)
+
+	for queue.Len() > 0 {
+		current, _ := queue.Pop()
+		if done[current] {
+			continue
+		}
+		done[current] = true
+
+		point := current.point
+		if point == rp.target {
+			return rp.restore(prev, current), true
+		}
+
+		nextPoints := rp.graph[point]
+		rp.recPointLists = append(rp.recPointLists, nextPoints)
+
+		for _, nextPoint := range nextPoints {
+			next := rp.stateOf(current, nextPoint)
+			if done[next] {
+				continue
+			}
+			d := dist[current] + rp.stepCost(current.dir, point, nextPoint)
+			if known, ok := dist[next]; !ok || d < known {
+				dist[next] = d
+				prev[next] = current
+				queue.Push(next, float64(d))
+			}
+		}
+	}
+	return Route{}, false
+}
+
+func (rp *plan) stateOf(from state, to PointOnMap) state {
+	if rp.metric != MetricTurns {
+		return state{point: to}
+	}
+	return state{point: to, dir: rp.rules.NextDirection(from.dir, from.point, to)}
+}
+
+func (rp *plan) stepCost(prevDir Direction, from, to PointOnMap) int {
+	return rp.rules.StepCost(rp.metric, prevDir, from, to)*costScale +
+		rp.rules.StepCost(rp.secondary, prevDir, from, to)
+}
+
+// restore собирает маршрут от цели к старту по ссылкам на предыдущие узлы
+func (rp *plan) restore(prev map[state]state, last state) Route {
+	route := Route{}
+	for current := last; ; current = prev[current] {
+		route.Add(current.point)
+		if current.point == rp.start {
+			break
+		}
+	}
+	return route.Reverse()
+}
@@ -0,0 +1,75 @@
+package ox
+
+import (
+	. "maze/internal/global"
+	"testing"
+)
+
+func TestBuildRoutes(t *testing.T) {
+
+	start, target := PointOnMap{0, 0}, PointOnMap{5, 5}
+
+	// короткий по перемещениям, но длинный по клеткам: 3 перемещения, 100 клеток
+	// длинный по перемещениям, но короткий по клеткам: 6 перемещений, 10 клеток
+	graph := RoutingStruct{
+		{0, 0}:  {{0, 50}, {1, 0}},
+		{0, 50}: {{0, 0}, {5, 50}},
+		{5, 50}: {{0, 50}, {5, 5}},
+		{1, 0}:  {{0, 0}, {1, 1}},
+		{1, 1}:  {{1, 0}, {2, 1}},
+		{2, 1}:  {{1, 1}, {2, 2}},
+		{2, 2}:  {{2, 1}, {5, 2}},
+		{5, 2}:  {{2, 2}, {5, 5}},
+		{5, 5}:  {},
+	}
+	rsProvider := func(reverted bool) RoutingStruct {
+		return graph
+	}
+
+	byMovesRoute := (&Route{}).Unserialize("[0 0] [0 50] [5 50] [5 5]")
+	byCellsRoute := (&Route{}).Unserialize("[0 0] [1 0] [1 1] [2 1] [2 2] [5 2] [5 5]")
+
+	testCases := []struct {
+		metric   Metric
+		expected []*Route
+	}{
+		{MetricMoves, []*Route{byMovesRoute, byCellsRoute, byMovesRoute, byCellsRoute}},
+		{MetricCells, []*Route{byCellsRoute, byMovesRoute, byMovesRoute, byCellsRoute}},
+	}
+
+	for _, tc := range testCases {
+		results := New(tc.metric, RouteRules{}).BuildRoutes(rsProvider, start, target, 6, 51)
+		if len(results) != len(tc.expected) {
+			t.Fatalf("Failure: expected %d routes, got %d", len(tc.expected), len(results))
+		}
+
+		for i, expected := range tc.expected {
+			t.Run("BuildRoutes("+string(tc.metric)+")", func(t *testing.T) {
+				result := results[i].Route
+				if !expected.Eq(&result) {
+					f := "Failure (#%d), EXPECT ≠ RESULT):\nEXPECT: %v\nRESULT: %v"
+					t.Errorf(f, i, *expected, result)
+				}
+			})
+		}
+	}
+}
+
+func TestBuildRoutes_NoRoute(t *testing.T) {
+
+	graph := RoutingStruct{
+		{0, 0}: {{0, 3}},
+		{0, 3}: {{0, 0}},
+		{5, 5}: {},
+	}
+	rsProvider := func(reverted bool) RoutingStruct {
+		return graph
+	}
+
+	t.Run("BuildRoutes()", func(t *testing.T) {
+		results := New(MetricMoves, RouteRules{}).BuildRoutes(rsProvider, PointOnMap{0, 0}, PointOnMap{5, 5}, 6, 6)
+		if len(results) != 0 {
+			t.Errorf("Failure: expected no routes, got %v", results)
+		}
+	})
+}
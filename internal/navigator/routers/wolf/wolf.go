@@ -317,7 +317,8 @@ func unloopingLine(route Route) Route {
 
 	tail := items[lastAddedIndex:]
 
-	if repeat := max(xRepeat, yRepeat); repeat > 2 {
+	// серия могла начаться до последней добавленной точки
+	if repeat := max(xRepeat, yRepeat); repeat > 2 && repeat <= len(tail) {
 		length := len(tail)
 		offset := length - repeat
 		lastPair := append(tail[offset:offset+1], tail[length-1:length]...)
@@ -0,0 +1,95 @@
+package navigator
+
+import (
+	. "maze/internal/global"
+	"maze/internal/world"
+	"slices"
+)
+
+// RouterReverse Имя поставщика маршрутов обратного поиска от выходов
+const RouterReverse = "reverse"
+
+// BuildRoutingGraph выполняет обход в ширину сразу от всех источников и
+// возвращает граф локаций. В отличие от дерева, дополнительные точки
+// становятся обычными узлами графа, через которые можно идти дальше.
+// Источники целями не считаются: перемещение на них не останавливается
+func BuildRoutingGraph(w *world.World, sources, nodes PointList) RoutingStruct {
+
+	graph := RoutingStruct{}
+	queue := slices.Clone(sources)
+	queueRegistry := PointRegistry{}
+	for _, source := range sources {
+		queueRegistry[source] = true
+	}
+	pointReg := PointRegistry{}
+
+	for i := 0; i < len(queue); i++ {
+		point := queue[i]
+		for _, mov := range w.FindNextMoves(point[0], point[1], nodes) {
+			nextPoint := PointOnMap{mov[0], mov[1]}
+			if nextPoint == point {
+				continue
+			}
+			pointReg[nextPoint] = true
+			if !queueRegistry[nextPoint] {
+				queue = append(queue, nextPoint)
+				queueRegistry[nextPoint] = true
+			}
+		}
+
+		pl := make(PointList, 0, len(pointReg))
+		for p := range pointReg {
+			pl = append(pl, p)
+		}
+		graph[point] = pl
+		clear(pointReg)
+	}
+
+	graph.SortPointsInValues()
+	return graph
+}
+
+// FindStartRoutes возвращает по одному лучшему по критерию маршруту из
+// каждого старта. Все маршруты находит один поиск Дейкстры от выходов
+// к стартам. Маршрут из недостижимого старта состоит из одной точки. Если
+// выходов нет, маршрутов тоже нет
+func FindStartRoutes(w *world.World, opts Options) []NavRoute {
+
+	starts := w.GetStartPoints()
+	exits := w.GetExitPoints()
+	if len(exits) == 0 {
+		return nil
+	}
+	// от выходов идём против движения: по развёрнутому графу из всех стартов
+	graph := ReverseRoutingStruct(BuildRoutingGraph(w, starts, exits))
+
+	// идём от выхода к старту, поэтому стоимость считаем для прямого перемещения
+	rules := w.Rules()
+	rules.Terrain = func(from, to PointOnMap) int {
+		return w.SlideCost(to, from)
+	}
+	paths := shortestPaths(graph, rules, opts.Metric, exits)
+
+	results := make([]NavRoute, 0, len(starts))
+	for _, start := range starts {
+		result := NavRoute{
+			RouterName: RouterReverse,
+			Route:      &Route{},
+			Start:      start,
+			Exit:       exits[0],
+		}
+		if slices.Contains(exits, start) {
+			result.Exit = start
+		}
+		// цепочка предшественников ведёт от старта к выходу
+		for i, p := range paths.chain(start) {
+			result.Route.Add(p)
+			if i > 0 {
+				result.Exit = p
+			}
+		}
+		result.Cost = result.Route.CostBy(w.Rules())
+		results = append(results, result)
+	}
+	return results
+}
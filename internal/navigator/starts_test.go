@@ -0,0 +1,129 @@
+package navigator
+
+import (
+	. "maze/internal/global"
+	"maze/internal/world"
+	"testing"
+)
+
+func TestFindStartRoutes(t *testing.T) {
+
+	w, err := world.Construct(`
+		wwwwwwwww
+		w@     Qw
+		w wwwww w
+		w@ w  @ w
+		wwwwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		start    PointOnMap
+		expected *Route
+	}{
+		{PointOnMap{1, 1}, (&Route{}).Unserialize("[1 1] [7 1]")},
+		{PointOnMap{1, 3}, (&Route{}).Unserialize("[1 3] [1 1] [7 1]")},
+		{PointOnMap{6, 3}, (&Route{}).Unserialize("[6 3] [7 3] [7 1]")},
+	}
+
+	results := FindStartRoutes(w, DefaultOptions())
+	if len(results) != len(testCases) {
+		t.Fatalf("Failure: expected %d routes, got %d", len(testCases), len(results))
+	}
+
+	for i, tc := range testCases {
+		t.Run("FindStartRoutes()", func(t *testing.T) {
+			result := results[i]
+			if result.Start != tc.start || !tc.expected.Eq(result.Route) || !result.IsFoundTarget() {
+				f := "Failure (#%d), EXPECT ≠ RESULT):\nEXPECT: %v\nRESULT: %v"
+				t.Errorf(f, i, *tc.expected, result)
+			}
+		})
+	}
+
+	if nearest := SelectBest(results, MetricMoves); nearest != 0 {
+		t.Errorf("Failure: expected nearest by moves start #0, got #%d", nearest)
+	}
+
+	opts := DefaultOptions()
+	opts.Metric = MetricCells
+	if nearest := SelectBest(FindStartRoutes(w, opts), MetricCells); nearest != 2 {
+		t.Errorf("Failure: expected nearest by cells start #2, got #%d", nearest)
+	}
+}
+
+func TestFindStartRoutes_Arrows(t *testing.T) {
+
+	// обратный поиск идёт от выхода против стрелки, но маршрут - по стрелке
+	w, err := world.Construct(`
+		wwwwwww
+		wQ < @w
+		w wwwww
+		w  >  w
+		wwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := (&Route{}).Unserialize("[5 1] [1 1]")
+	results := FindStartRoutes(w, DefaultOptions())
+	if len(results) != 1 || !expected.Eq(results[0].Route) {
+		t.Errorf("Failure: expected %v, got %v", *expected, results)
+	}
+
+	w, err = world.Construct(`
+		wwwwwww
+		wQ > @w
+		w wwwww
+		w    ww
+		wwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results := FindStartRoutes(w, DefaultOptions()); len(results) != 1 || results[0].IsFoundTarget() {
+		t.Errorf("Failure: route against the arrow found: %v", results)
+	}
+}
+
+func TestFindStartRoutes_NoExit(t *testing.T) {
+
+	w, err := world.Construct(`
+		wwwww
+		w@  w
+		wwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results := FindStartRoutes(w, DefaultOptions()); len(results) != 0 {
+		t.Errorf("Failure: expected no routes without exit, got %v", results)
+	}
+}
+
+func TestFindStartRoutes_Slide(t *testing.T) {
+
+	// скольжение из левого старта не останавливается на правом
+	w, err := world.Construct(`
+		wwwwwww
+		w@ @  w
+		www www
+		www www
+		wwwQwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetMovement(MovementSlide)
+
+	results := FindStartRoutes(w, DefaultOptions())
+	if len(results) != 2 || results[0].IsFoundTarget() || !results[1].IsFoundTarget() {
+		t.Fatalf("Failure: expected start #0 unreachable and #1 reachable, got %v", results)
+	}
+	if err := world.ValidateRoute(w, results[1].Route); err != nil {
+		t.Errorf("Failure on %v: %v", results[1], err)
+	}
+}
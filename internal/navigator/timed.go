@@ -0,0 +1,69 @@
+package navigator
+
+import (
+	. "maze/internal/global"
+	"maze/internal/navigator/routers/mole"
+	"maze/internal/world"
+)
+
+// timeState Такт по модулю периода карты: через период ворота и охранники
+// повторяются, поэтому больше ничего о времени помнить не нужно
+type timeState struct {
+	t int
+}
+
+// timeSpace Пространство состояний (позиция, такт). Ожидание на месте -
+// обычный переход длиной в один такт
+type timeSpace struct {
+	w       *world.World
+	period  int
+	targets PointList
+	exit    PointOnMap
+}
+
+func (ts *timeSpace) Next(s mole.State[timeState]) []mole.Move[timeState] {
+
+	from := s.Point
+	var moves []mole.Move[timeState]
+	for _, mov := range ts.w.FindMovesAt(from[0], from[1], s.Data.t, ts.targets) {
+		to := PointOnMap{mov[0], mov[1]}
+		next := timeState{t: (s.Data.t + mov[2]) % ts.period}
+		// при равном времени прибытия выбираем маршрут с меньшим числом клеток
+		cost := mov[2]*costScale + CellsCost(from, to)
+		moves = append(moves, mole.Move[timeState]{To: mole.State[timeState]{Point: to, Data: next}, Cost: cost})
+	}
+	return moves
+}
+
+func (ts *timeSpace) IsTarget(s mole.State[timeState]) bool {
+	return s.Point == ts.exit
+}
+
+// findTimedRoutes прокладывает маршруты роутером mole по карте с воротами
+// и охранниками: по одному из каждого старта к каждому выходу, с самым
+// ранним прибытием
+func findTimedRoutes(w *world.World) []NavRoute {
+
+	exits := w.GetExitPoints()
+	var results []NavRoute
+	for _, start := range w.GetStartPoints() {
+		for _, exit := range exits {
+			space := &timeSpace{w: w, period: w.Period(), targets: exits, exit: exit}
+			result, _, ok := mole.New[timeState](space).Build(mole.State[timeState]{Point: start})
+			if !ok {
+				continue
+			}
+			route := w.Fold(result.Route)
+			results = append(results, NavRoute{
+				RouterName:    RouterMole,
+				Route:         &route,
+				RecPointLists: result.RecPointLists,
+				Cost:          route.CostBy(w.Rules()),
+				Time:          w.RouteTime(&route),
+				Start:         start,
+				Exit:          exit,
+			})
+		}
+	}
+	return results
+}
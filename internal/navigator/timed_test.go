@@ -0,0 +1,35 @@
+package navigator
+
+import (
+	"maze/internal/world"
+	"testing"
+)
+
+func TestFindRoutes_MoleTimed(t *testing.T) {
+
+	w, err := world.Construct(`
+		wwwwwww
+		w@ #  Q
+		w     w
+		wwwwwww
+		[gates]
+		3,1 1 3
+		[guards]
+		5,1 5,2 4,2 4,1 5,1
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := FindRoutes(w, RouterMole, DefaultOptions())
+	if len(routes) != 1 || !routes[0].IsFoundTarget() {
+		t.Fatalf("Failure: expected one route to exit, got %v", routes)
+	}
+	// без ожидания ворота закрыты: ждём, пока они откроются на такте 4
+	if routes[0].Time != 7 {
+		t.Errorf("Failure: expected time 7, got %d", routes[0].Time)
+	}
+	if err := world.ValidateRoute(w, routes[0].Route); err != nil {
+		t.Errorf("Failure: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package tour
+
+import (
+	"math"
+	"slices"
+)
+
+// Порядок обхода точек по матрице расстояний: путь начинается в точке 0,
+// заканчивается в последней точке, а промежуточные точки 1..n нужно обойти
+// все, в любом порядке. Расстояния могут быть несимметричными (стрелки).
+// Порядок нужен и маршрутам через обязательные точки, и сбору предметов
+
+// Unreachable Расстояние до недостижимой точки
+const Unreachable = math.MaxInt / 4
+
+// MaxExact Наибольшее число промежуточных точек, для которого порядок
+// ищется точно (Held-Karp). Память и время растут как 2^n
+const MaxExact = 15
+
+// Order возвращает порядок обхода промежуточных точек и длину пути: точный
+// для небольшого числа точек, иначе - ближайший сосед, улучшенный 2-opt
+func Order(dist [][]int) ([]int, int) {
+	if len(dist)-2 <= MaxExact {
+		return HeldKarp(dist)
+	}
+	order := TwoOpt(dist, NearestNeighbor(dist))
+	return order, Length(dist, order)
+}
+
+// Length возвращает длину пути через промежуточные точки в заданном порядке
+func Length(dist [][]int, order []int) int {
+	length, prev := 0, 0
+	for _, i := range append(slices.Clone(order), len(dist)-1) {
+		length = add(length, dist[prev][i])
+		prev = i
+	}
+	return length
+}
+
+// HeldKarp находит порядок обхода динамическим программированием по
+// подмножествам: cost[mask][last] - длина кратчайшего пути из точки 0 через
+// точки из mask, заканчивающегося в last
+func HeldKarp(dist [][]int) ([]int, int) {
+
+	n := len(dist) - 2
+	if n <= 0 {
+		return []int{}, Length(dist, nil)
+	}
+
+	full := 1<<n - 1
+	cost := make([][]int, full+1)
+	parent := make([][]int, full+1)
+	for mask := range cost {
+		cost[mask] = make([]int, n)
+		parent[mask] = make([]int, n)
+		for i := range cost[mask] {
+			cost[mask][i] = Unreachable
+		}
+	}
+	for i := 0; i < n; i++ {
+		cost[1<<i][i] = dist[0][i+1]
+	}
+
+	for mask := 1; mask <= full; mask++ {
+		for last := 0; last < n; last++ {
+			c := cost[mask][last]
+			if mask&(1<<last) == 0 || c >= Unreachable {
+				continue
+			}
+			for next := 0; next < n; next++ {
+				if mask&(1<<next) != 0 {
+					continue
+				}
+				nextMask := mask | 1<<next
+				if nc := add(c, dist[last+1][next+1]); nc < cost[nextMask][next] {
+					cost[nextMask][next] = nc
+					parent[nextMask][next] = last
+				}
+			}
+		}
+	}
+
+	best, bestLast := Unreachable, 0
+	for last := 0; last < n; last++ {
+		if c := add(cost[full][last], dist[last+1][n+1]); c < best {
+			best, bestLast = c, last
+		}
+	}
+	if best >= Unreachable {
+		return NearestNeighbor(dist), Unreachable
+	}
+
+	order := make([]int, n)
+	for mask, last, i := full, bestLast, n-1; i >= 0; i-- {
+		order[i] = last + 1
+		mask, last = mask&^(1<<last), parent[mask][last]
+	}
+	return order, best
+}
+
+// NearestNeighbor строит порядок обхода, каждый раз переходя в ближайшую
+// из ещё не посещённых точек
+func NearestNeighbor(dist [][]int) []int {
+	n := len(dist) - 2
+	visited := make([]bool, n+1)
+	order := make([]int, 0, n)
+	for prev := 0; len(order) < n; {
+		next := 0
+		for i := 1; i <= n; i++ {
+			if !visited[i] && (next == 0 || dist[prev][i] < dist[prev][next]) {
+				next = i
+			}
+		}
+		visited[next] = true
+		order = append(order, next)
+		prev = next
+	}
+	return order
+}
+
+// TwoOpt улучшает порядок обхода, разворачивая его отрезки, пока путь
+// становится короче. Расстояния несимметричны, поэтому длина пересчитывается
+// целиком
+func TwoOpt(dist [][]int, order []int) []int {
+	best := slices.Clone(order)
+	bestLength := Length(dist, best)
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < len(best)-1; i++ {
+			for j := i + 1; j < len(best); j++ {
+				candidate := slices.Clone(best)
+				slices.Reverse(candidate[i : j+1])
+				if length := Length(dist, candidate); length < bestLength {
+					best, bestLength, improved = candidate, length, true
+				}
+			}
+		}
+	}
+	return best
+}
+
+func add(a, b int) int {
+	if a >= Unreachable || b >= Unreachable {
+		return Unreachable
+	}
+	return a + b
+}
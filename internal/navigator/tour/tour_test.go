@@ -0,0 +1,80 @@
+package tour
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// lineDist Точки на прямой в заданных координатах
+func lineDist(xs []int) [][]int {
+	dist := make([][]int, len(xs))
+	for i := range xs {
+		dist[i] = make([]int, len(xs))
+		for j := range xs {
+			dist[i][j] = max(xs[i]-xs[j], xs[j]-xs[i])
+		}
+	}
+	return dist
+}
+
+func TestHeldKarp(t *testing.T) {
+
+	type testCase struct {
+		xs     []int
+		order  []int
+		length int
+	}
+
+	testCases := []testCase{
+		{[]int{0, 9}, []int{}, 9},
+		{[]int{0, 5, 9}, []int{1}, 9},
+		{[]int{0, 7, 3, 5, 9}, []int{2, 3, 1}, 9},
+		{[]int{5, 9, 0, 3}, []int{1, 2}, 16}, // сначала вправо, потом влево
+	}
+
+	for _, tc := range testCases {
+		t.Run("HeldKarp()", func(t *testing.T) {
+			order, length := HeldKarp(lineDist(tc.xs))
+			if !slices.Equal(order, tc.order) || length != tc.length {
+				t.Errorf("Failure on %v: expected %v %d, got %v %d", tc.xs, tc.order, tc.length, order, length)
+			}
+		})
+	}
+
+	// точка 1 недостижима
+	dist := lineDist([]int{0, 5, 9})
+	dist[0][1], dist[2][1] = Unreachable, Unreachable
+	if _, length := HeldKarp(dist); length != Unreachable {
+		t.Errorf("Failure: expected unreachable, got %d", length)
+	}
+}
+
+func TestTwoOpt(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+	xs := []int{0}
+	for i := 0; i < 20; i++ {
+		xs = append(xs, rnd.Intn(100))
+	}
+	xs = append(xs, 100)
+	dist := lineDist(xs)
+
+	// на прямой лучший путь из 0 в 100 идёт слева направо
+	order, length := Order(dist)
+	if length != 100 || len(order) != 20 {
+		t.Errorf("Failure on %v: expected length 100, got %v %d", xs, order, length)
+	}
+	for i := 1; i < len(order); i++ {
+		if xs[order[i-1]] > xs[order[i]] {
+			t.Errorf("Failure on %v: order %v is not sorted", xs, order)
+			break
+		}
+	}
+
+	// на небольшом числе точек эвристика совпадает с точным решением
+	small := lineDist([]int{0, 7, 3, 5, 1, 8, 9})
+	if _, exact := HeldKarp(small); Length(small, TwoOpt(small, NearestNeighbor(small))) != exact {
+		t.Errorf("Failure: 2-opt differs from exact length %d", exact)
+	}
+}
@@ -0,0 +1,67 @@
+package world
+
+import (
+	. "maze/internal/global"
+)
+
+// Клетки со стрелками проходятся только в направлении стрелки
+const (
+	ArrowLeft  = '<'
+	ArrowRight = '>'
+	ArrowUp    = '^'
+	ArrowDown  = 'v'
+)
+
+// arrowDirections направление каждой стрелки
+var arrowDirections = map[byte]Direction{
+	ArrowLeft:  {-1, 0},
+	ArrowRight: {1, 0},
+	ArrowUp:    {0, -1},
+	ArrowDown:  {0, 1},
+}
+
+// arrowReversals стрелка, указывающая в обратную сторону
+var arrowReversals = map[byte]byte{
+	ArrowLeft:  ArrowRight,
+	ArrowRight: ArrowLeft,
+	ArrowUp:    ArrowDown,
+	ArrowDown:  ArrowUp,
+}
+
+// IsArrow проверяет, что клетка проходится только в одну сторону
+func IsArrow(v byte) bool {
+	_, ok := arrowDirections[v]
+	return ok
+}
+
+// canStep проверяет шаг из клетки `[x, y]` в соседнюю `[x+dx, y+dy]`: в
+// клетку со стрелкой можно войти и выйти из неё только по направлению стрелки
+func (w *World) canStep(x, y, dx, dy int) bool {
+	return w.canEnter(PointOnMap{x, y}, PointOnMap{x + dx, y + dy}, Direction{dx, dy})
+}
+
+// canEnter проверяет шаг из клетки `from` в клетку `to` в направлении `dir`:
+// в соседнюю или, через край карты, на противоположный край
+func (w *World) canEnter(from, to PointOnMap, dir Direction) bool {
+	if !w.moveablePoint(to[0], to[1]) {
+		return false
+	}
+	for _, p := range []PointOnMap{from, to} {
+		if arrow, ok := arrowDirections[w.GetPoint(p[0], p[1])]; ok && arrow != dir {
+			return false
+		}
+	}
+	return true
+}
+
+// Revert разворачивает все стрелки. На развёрнутой карте маршрут от выхода
+// к старту - это развёрнутый маршрут от старта к выходу на исходной карте
+func (w *World) Revert() {
+	for y := 0; y < w.height; y++ {
+		for x := 0; x < w.width; x++ {
+			if reversal, ok := arrowReversals[w.GetPoint(x, y)]; ok {
+				w.SetPoint(x, y, reversal)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package world
+
+import (
+	"fmt"
+	. "maze/internal/global"
+	"strings"
+)
+
+const (
+	// Checkpoint Обязательная точка: маршрут должен пройти через все такие
+	// клетки, в любом порядке
+	Checkpoint = '!'
+
+	// Item Предмет: в режиме сбора маршрут должен собрать все предметы
+	Item = '$'
+)
+
+// GetCheckpoints возвращает обязательные точки карты (по строкам, слева направо)
+func (w *World) GetCheckpoints() PointList {
+	return w.findSymbol(Checkpoint)
+}
+
+// GetItemPoints возвращает клетки с предметами (по строкам, слева направо)
+func (w *World) GetItemPoints() PointList {
+	return w.findSymbol(Item)
+}
+
+// findSymbol возвращает клетки с символом `v`
+func (w *World) findSymbol(v byte) PointList {
+	var points PointList
+	for y := 0; y < w.height; y++ {
+		for x := 0; x < w.width; x++ {
+			if w.GetPoint(x, y) == v {
+				points = append(points, PointOnMap{x, y})
+			}
+		}
+	}
+	return points
+}
+
+// ParseCheckpoints читает список обязательных точек вида "x,y;x,y"
+func (w *World) ParseCheckpoints(src string) (PointList, error) {
+	var points PointList
+	for _, field := range strings.Split(src, ";") {
+		p, err := w.parsePoint(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		if !w.moveablePoint(p[0], p[1]) {
+			return nil, fmt.Errorf("checkpoint %v is not a free cell", p)
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// RouteCells возвращает все клетки, через которые проходит маршрут
+func (w *World) RouteCells(route *Route) PointRegistry {
+	cells := PointRegistry{}
+	for _, p := range w.routeTrail(route) {
+		cells[p] = true
+	}
+	return cells
+}
+
+// IsLoopless проверяет, что маршрут не проходит ни одну клетку дважды
+func (w *World) IsLoopless(route *Route) bool {
+	return len(w.routeTrail(route)) == len(w.RouteCells(route))
+}
+
+// routeTrail возвращает клетки маршрута по порядку прохождения
+func (w *World) routeTrail(route *Route) PointList {
+	trail := w.unfold(*route)
+	return trail.GetItems()
+}
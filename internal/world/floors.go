@@ -0,0 +1,96 @@
+package world
+
+import (
+	"fmt"
+	. "maze/internal/global"
+	"strings"
+)
+
+// Многоэтажная карта: этажи идут в тексте друг под другом и разделяются
+// строкой из дефисов. Координаты точки - позиция в тексте (с учётом
+// разделителей), этаж определяется по строке. Лестница U ведёт на этаж выше
+// в клетку D с теми же координатами на этаже, и обратно
+const (
+	Upstairs       = 'U'
+	Downstairs     = 'D'
+	FloorSeparator = '-'
+)
+
+// isSeparatorLine проверяет, что строка текста разделяет этажи
+func isSeparatorLine(line string) bool {
+	return len(line) >= 3 && strings.Trim(line, string(FloorSeparator)) == ""
+}
+
+// findFloors находит первую строку каждого этажа
+func (w *World) findFloors() {
+	w.floors = []int{0}
+	for y := 0; y < w.height; y++ {
+		if isSeparatorLine(string(w.geoMap[y])) {
+			w.floors = append(w.floors, y+1)
+		}
+	}
+}
+
+// findStairs связывает лестницы вверх с лестницами вниз на этаже выше.
+// Лестница - такой же прыжок, как и телепорт
+func (w *World) findStairs() error {
+	for y := 0; y < w.height; y++ {
+		for x := 0; x < w.width; x++ {
+			p := PointOnMap{x, y}
+			if w.GetPoint(x, y) != Upstairs {
+				continue
+			}
+			p3 := w.To3D(p)
+			above, ok := w.From3D(PointOnMap3D{p3[0], p3[1], p3[2] + 1})
+			if !ok || w.GetPoint(above[0], above[1]) != Downstairs {
+				return fmt.Errorf("upstairs '%c' at %v has no downstairs '%c' on the floor above", Upstairs, p, Downstairs)
+			}
+			w.portals[p], w.portals[above] = above, p
+		}
+	}
+	for y := 0; y < w.height; y++ {
+		for x := 0; x < w.width; x++ {
+			if _, ok := w.portals[PointOnMap{x, y}]; w.GetPoint(x, y) == Downstairs && !ok {
+				return fmt.Errorf("downstairs '%c' at %v has no upstairs '%c' on the floor below", Downstairs, PointOnMap{x, y}, Upstairs)
+			}
+		}
+	}
+	return nil
+}
+
+// GetFloors возвращает число этажей
+func (w *World) GetFloors() int {
+	return len(w.floors)
+}
+
+// Floor возвращает этаж строки `y`
+func (w *World) Floor(y int) int {
+	z := 0
+	for z+1 < len(w.floors) && w.floors[z+1] <= y {
+		z++
+	}
+	return z
+}
+
+// floorHeight возвращает число строк этажа без разделителя
+func (w *World) floorHeight(z int) int {
+	if z+1 < len(w.floors) {
+		return w.floors[z+1] - w.floors[z] - 1
+	}
+	return w.height - w.floors[z]
+}
+
+// To3D возвращает координаты точки на её этаже и номер этажа
+func (w *World) To3D(p PointOnMap) PointOnMap3D {
+	z := w.Floor(p[1])
+	return PointOnMap3D{p[0], p[1] - w.floors[z], z}
+}
+
+// From3D возвращает точку карты по координатам на этаже
+func (w *World) From3D(p PointOnMap3D) (PointOnMap, bool) {
+	x, y, z := p[0], p[1], p[2]
+	if z < 0 || z >= len(w.floors) || y < 0 || y >= w.floorHeight(z) || !w.inBounds(x, 0) {
+		return PointOnMap{}, false
+	}
+	return PointOnMap{x, w.floors[z] + y}, true
+}
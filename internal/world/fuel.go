@@ -0,0 +1,65 @@
+package world
+
+import (
+	. "maze/internal/global"
+)
+
+// Refuel Заправка: проходя клетку, заполняем бак до полного
+const Refuel = 'F'
+
+// SetFuel задаёт объём бака: каждая пройденная клетка сжигает единицу
+// топлива, старт - с полным баком. Ноль - топливо не ограничено
+func (w *World) SetFuel(fuel int) {
+	w.fuel = fuel
+}
+
+func (w *World) Fuel() int {
+	return w.fuel
+}
+
+// GetRefuelPoints возвращает клетки с заправками
+func (w *World) GetRefuelPoints() PointList {
+	return w.findSymbol(Refuel)
+}
+
+// FuelAfter возвращает остаток топлива после перемещения, начатого с
+// запасом `fuel`. Телепорт и ожидание на месте топлива не сжигают.
+// Отрицательное значение - топлива не хватило
+func (w *World) FuelAfter(from, to PointOnMap, fuel int) int {
+	if w.fuel == 0 || from == to || w.IsHop(from, to) {
+		return fuel
+	}
+	if !w.isStraight(from, to) && !w.isLegal(from, to) {
+		// такого перемещения нет, заправки на нём не считаем
+		return max(fuel-w.Rules().StepCost(MetricCells, Direction{}, from, to), -1)
+	}
+	dir := w.directionOf(from, to)
+	for p := from; p != to; {
+		p = w.nextCell(p, dir)
+		if fuel--; fuel < 0 {
+			return -1
+		}
+		if w.GetPoint(p[0], p[1]) == Refuel {
+			fuel = w.fuel
+		}
+	}
+	return fuel
+}
+
+// RouteFuel возвращает запас топлива в каждой точке маршрута или nil, если
+// топливо не ограничено
+func (w *World) RouteFuel(route *Route) []int {
+	if w.fuel == 0 {
+		return nil
+	}
+	items := route.GetItems()
+	levels := make([]int, 0, len(items))
+	fuel := w.fuel
+	for i := range items {
+		if i > 0 && fuel >= 0 {
+			fuel = w.FuelAfter(items[i-1], items[i], fuel)
+		}
+		levels = append(levels, fuel)
+	}
+	return levels
+}
@@ -0,0 +1,119 @@
+package world
+
+import (
+	"fmt"
+	. "maze/internal/global"
+	"slices"
+)
+
+// Шестиугольная сетка задаётся секцией карты [grid] со строкой "hex". Текст
+// карты не меняется: нечётные строки считаются сдвинутыми на полклетки
+// вправо (см. HexDirections)
+const (
+	gridSection = "grid"
+	gridSquare  = "square"
+	gridHex     = "hex"
+)
+
+// applyGrid читает форму клеток из секции [grid]
+func (w *World) applyGrid(lines []string) error {
+	for _, line := range lines {
+		switch line {
+		case gridSquare:
+			w.hex = false
+		case gridHex:
+			w.hex = true
+		default:
+			return fmt.Errorf("bad grid line %q, expected %q or %q", line, gridSquare, gridHex)
+		}
+	}
+	return nil
+}
+
+// Grid возвращает строки секции [grid]
+func (w *World) Grid() []string {
+	if w.hex {
+		return []string{gridHex}
+	}
+	return nil
+}
+
+// IsHex проверяет, что карта на шестиугольной сетке
+func (w *World) IsHex() bool {
+	return w.hex
+}
+
+// lineDirections направления перемещения по прямым сетки
+func (w *World) lineDirections() []Direction {
+	if w.hex {
+		return HexDirections
+	}
+	return directions4
+}
+
+// stepDirections направления шага на соседнюю клетку в модели перемещения
+func (w *World) stepDirections() []Direction {
+	if w.movement == MovementKing && !w.hex {
+		return directions8
+	}
+	return w.lineDirections()
+}
+
+// isStraight проверяет, что точки лежат на одной прямой сетки
+func (w *World) isStraight(from, to PointOnMap) bool {
+	if w.hex {
+		_, ok := HexDirectionOf(from, to)
+		return ok
+	}
+	return from[0] == to[0] || from[1] == to[1]
+}
+
+// isLegal проверяет, что перемещение возможно в модели перемещения
+// (без учёта карты)
+func (w *World) isLegal(from, to PointOnMap) bool {
+	if w.hex {
+		return w.movement.IsLegalHex(from, to)
+	}
+	return w.movement.IsLegal(from, to)
+}
+
+// findLines возвращает перемещения по прямым шестиугольной сетки или
+// квадратной сетки со сквозными краями: как и в findHorizontals,
+// findVerticals, остановки - это клетки, из которых можно свернуть на другую
+// прямую, а также видимые по прямой цели и телепорты. Прямая продолжается
+// за краем карты, пока не вернётся в точку старта
+func (w *World) findLines(fromX, fromY int, targets PointList) []GeoPosition {
+
+	var moves []GeoPosition
+	from := PointOnMap{fromX, fromY}
+
+	if twin, ok := w.Twin(from); ok {
+		moves = append(moves, GeoPosition{twin[0], twin[1], 0})
+	}
+
+	nodes := append(w.GetPortalPoints(), targets...)
+	for _, dir := range w.lineDirections() {
+		for p := from; w.canEnter(p, w.nextCell(p, dir), dir) && w.nextCell(p, dir) != from; {
+			p = w.nextCell(p, dir)
+			if w.directionOf(from, p) != dir {
+				// в эту клетку ведёт другой путь по той же прямой
+				continue
+			}
+			if slices.Contains(nodes, p) || w.canTurn(p, dir) {
+				moves = append(moves, GeoPosition{p[0], p[1], w.SlideCost(from, p)})
+			}
+		}
+	}
+	return moves
+}
+
+// canTurn проверяет, что из клетки `p`, куда пришли в направлении `dir`,
+// можно уйти по другой прямой
+func (w *World) canTurn(p PointOnMap, dir Direction) bool {
+	for _, turn := range w.lineDirections() {
+		if turn != dir && turn != (Direction{-dir[0], -dir[1]}) && w.canEnter(p, w.nextCell(p, turn), turn) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package world
+
+import (
+	. "maze/internal/global"
+)
+
+// KeySet Множество собранных ключей, по биту на букву
+type KeySet uint32
+
+// IsKey ключ - строчная буква, кроме стены и стрелки вниз
+func IsKey(v byte) bool {
+	return 'a' <= v && v <= 'z' && v != Wall && v != ArrowDown
+}
+
+// IsDoor дверь - заглавная буква, кроме выхода, лестниц и заправки; ключ от
+// двери - та же строчная
+func IsDoor(v byte) bool {
+	return 'A' <= v && v <= 'Z' && v != Exit && v != Upstairs && v != Downstairs && v != Refuel && IsKey(KeyOf(v))
+}
+
+// KeyOf возвращает ключ от двери
+func KeyOf(door byte) byte {
+	return door - 'A' + 'a'
+}
+
+func (ks KeySet) Has(key byte) bool {
+	return ks&(1<<(key-'a')) != 0
+}
+
+func (ks KeySet) With(key byte) KeySet {
+	return ks | 1<<(key-'a')
+}
+
+func (ks KeySet) String() string {
+	var keys []byte
+	for key := byte('a'); key <= 'z'; key++ {
+		if ks.Has(key) {
+			keys = append(keys, key)
+		}
+	}
+	return string(keys)
+}
+
+// OpenDoors открывает двери, ключи от которых собраны. Остальные двери
+// остаются стенами для FindNextMoves и проверки маршрутов
+func (w *World) OpenDoors(keys KeySet) {
+	w.openDoors = keys
+}
+
+// GetKeyPoints возвращает клетки с ключами
+func (w *World) GetKeyPoints() PointList {
+	var points PointList
+	for y := 0; y < w.height; y++ {
+		for x := 0; x < w.width; x++ {
+			if IsKey(w.GetPoint(x, y)) {
+				points = append(points, PointOnMap{x, y})
+			}
+		}
+	}
+	return points
+}
+
+// KeysOn возвращает ключи, через которые проходит перемещение по прямой,
+// шаг по диагонали или через край карты (без начальной точки), в порядке
+// прохода
+func (w *World) KeysOn(from, to PointOnMap) []byte {
+	if w.IsHop(from, to) || !w.isLegal(from, to) && !w.isStraight(from, to) ||
+		!w.inBounds(from[0], from[1]) || !w.inBounds(to[0], to[1]) {
+		return nil
+	}
+	var keys []byte
+	dir := w.directionOf(from, to)
+	for p := from; p != to; {
+		p = w.nextCell(p, dir)
+		if v := w.GetPoint(p[0], p[1]); IsKey(v) {
+			keys = append(keys, v)
+		}
+	}
+	return keys
+}
+
+// CollectKeys возвращает ключи в порядке их сбора на маршруте
+func (w *World) CollectKeys(route *Route) string {
+	var keys KeySet
+	var order []byte
+	items := route.GetItems()
+	for i := 1; i < len(items); i++ {
+		for _, key := range w.KeysOn(items[i-1], items[i]) {
+			if !keys.Has(key) {
+				keys = keys.With(key)
+				order = append(order, key)
+			}
+		}
+	}
+	return string(order)
+}
@@ -0,0 +1,133 @@
+package world
+
+import (
+	. "maze/internal/global"
+	"slices"
+)
+
+// directions4 направления перемещения по вертикали и горизонтали
+var directions4 = []Direction{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+// directions8 направления перемещения, включая диагонали
+var directions8 = append(slices.Clone(directions4), Direction{1, 1}, Direction{1, -1}, Direction{-1, 1}, Direction{-1, -1})
+
+// SetMovement задаёт модель перемещения
+func (w *World) SetMovement(m Movement) {
+	w.movement = m
+}
+
+func (w *World) Movement() Movement {
+	return w.movement
+}
+
+// findSlides возвращает остановки при скольжении по льду: в каждом
+// направлении скользим до стены или границы карты (сквозной край скольжение
+// не останавливает). Цель на пути скольжения тоже остановка (через выход
+// покидаем лабиринт), а скольжение по кругу без стен не останавливается
+func (w *World) findSlides(fromX, fromY int, targets PointList) []GeoPosition {
+
+	var moves []GeoPosition
+	from := PointOnMap{fromX, fromY}
+
+	if twin, ok := w.Twin(from); ok {
+		moves = append(moves, GeoPosition{twin[0], twin[1], 0})
+	}
+
+	for _, dir := range w.lineDirections() {
+		p := from
+		for w.canEnter(p, w.nextCell(p, dir), dir) && w.nextCell(p, dir) != from {
+			p = w.nextCell(p, dir)
+			if w.canEnter(p, w.nextCell(p, dir), dir) && slices.Contains(targets, p) && w.directionOf(from, p) == dir {
+				moves = append(moves, GeoPosition{p[0], p[1], w.SlideCost(from, p)})
+			}
+		}
+		if p != from && w.nextCell(p, dir) != from && w.directionOf(from, p) == dir {
+			moves = append(moves, GeoPosition{p[0], p[1], w.SlideCost(from, p)})
+		}
+	}
+	return moves
+}
+
+// findSteps возвращает соседние клетки, в которые можно шагнуть в заданных
+// направлениях, в том числе через край карты. Цели не нужны: соседняя цель и
+// так попадает в шаги
+func (w *World) findSteps(fromX, fromY int, dirs []Direction) []GeoPosition {
+
+	var moves []GeoPosition
+	from := PointOnMap{fromX, fromY}
+
+	if twin, ok := w.Twin(from); ok {
+		moves = append(moves, GeoPosition{twin[0], twin[1], 0})
+	}
+	for _, dir := range dirs {
+		to := w.nextCell(from, dir)
+		if !w.canEnter(from, to, dir) || w.cutsCorner(fromX, fromY, dir[0], dir[1]) {
+			continue
+		}
+		moves = append(moves, GeoPosition{to[0], to[1], w.SlideCost(from, to)})
+	}
+	return moves
+}
+
+// cutsCorner проверяет, что шаг по диагонали протискивается между двумя
+// стенами, стоящими углом друг к другу
+func (w *World) cutsCorner(x, y, dx, dy int) bool {
+	return !w.hex && dx != 0 && dy != 0 && !w.moveablePoint(x+dx, y) && !w.moveablePoint(x, y+dy)
+}
+
+// isSlideStop проверяет, что скольжение из `from` заканчивается в `to`
+func (w *World) isSlideStop(from, to PointOnMap) bool {
+	if from == to || slices.Contains(w.GetExitPoints(), to) {
+		return true
+	}
+	dir := w.directionOf(from, to)
+	return !w.canEnter(to, w.nextCell(to, dir), dir)
+}
+
+// Unfold разбивает перемещения по прямой на шаги по одной клетке, если модель
+// перемещения не позволяет уйти дальше соседней клетки. Роутеры, которые
+// спрямляют маршрут (fox, wolf), склеивают такие шаги в одно перемещение
+func (w *World) Unfold(route Route) Route {
+	if w.movement != MovementStep && w.movement != MovementKing {
+		return route
+	}
+	return w.unfold(route)
+}
+
+func (w *World) unfold(route Route) Route {
+	items := route.GetItems()
+	result := Route{}
+	for i, to := range items {
+		if i > 0 && !w.IsHop(items[i-1], to) && w.isStraight(items[i-1], to) {
+			dir := w.directionOf(items[i-1], to)
+			for p := items[i-1]; p != to; {
+				if p = w.nextCell(p, dir); p != to {
+					result.Add(p)
+				}
+			}
+		}
+		result.Add(to)
+	}
+	return result
+}
+
+// Fold склеивает идущие подряд шаги в одном направлении в одно перемещение,
+// если модель перемещения это позволяет (rook). Ожидание на месте и телепорты
+// не склеиваются, а склеенное перемещение должно идти в том же направлении
+// (у сквозных краёв путь между точками выбирается по карте)
+func (w *World) Fold(route Route) Route {
+	if w.movement != MovementRook {
+		return route
+	}
+	items := route.GetItems()
+	result := Route{}
+	for i, p := range items {
+		if i > 0 && i < len(items)-1 && !w.IsHop(items[i-1], p) && !w.IsHop(p, items[i+1]) &&
+			p != items[i-1] && w.directionOf(items[i-1], p) == w.directionOf(p, items[i+1]) &&
+			w.directionOf(items[i-1], items[i+1]) == w.directionOf(p, items[i+1]) {
+			continue
+		}
+		result.Add(p)
+	}
+	return result
+}
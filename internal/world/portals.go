@@ -0,0 +1,67 @@
+package world
+
+import (
+	"fmt"
+	. "maze/internal/global"
+	"slices"
+)
+
+// IsPortal телепорт - цифра от 1 до 9, каждая встречается на карте ровно
+// дважды
+func IsPortal(v byte) bool {
+	return '1' <= v && v <= '9'
+}
+
+// findPortals связывает клетки-телепорты с их парами
+func (w *World) findPortals() error {
+	cells := map[byte]PointList{}
+	for y := 0; y < w.height; y++ {
+		for x := 0; x < w.width; x++ {
+			if v := w.GetPoint(x, y); IsPortal(v) {
+				cells[v] = append(cells[v], PointOnMap{x, y})
+			}
+		}
+	}
+
+	w.portals = map[PointOnMap]PointOnMap{}
+	for v, points := range cells {
+		if len(points) != 2 {
+			return fmt.Errorf("portal '%c' must appear exactly twice, found %d", v, len(points))
+		}
+		w.portals[points[0]], w.portals[points[1]] = points[1], points[0]
+	}
+	return nil
+}
+
+// Twin возвращает пару телепорта
+func (w *World) Twin(p PointOnMap) (PointOnMap, bool) {
+	twin, ok := w.portals[p]
+	return twin, ok
+}
+
+// IsHop проверяет, что перемещение - прыжок через телепорт
+func (w *World) IsHop(from, to PointOnMap) bool {
+	twin, ok := w.portals[from]
+	return ok && twin == to
+}
+
+// GetPortalPoints возвращает все клетки-телепорты
+func (w *World) GetPortalPoints() PointList {
+	points := make(PointList, 0, len(w.portals))
+	for p := range w.portals {
+		points = append(points, p)
+	}
+	slices.SortFunc(points, func(a, b PointOnMap) int {
+		return comparePositions(GeoPosition{a[0], a[1]}, GeoPosition{b[0], b[1]})
+	})
+	return points
+}
+
+// Rules возвращает правила карты для проверки и подсчёта маршрутов
+func (w *World) Rules() RouteRules {
+	rules := RouteRules{Terrain: w.SlideCost, Cells: w.moveCells, IsHop: w.IsHop, Movement: w.movement, IsWrap: w.IsWrap, Hex: w.hex}
+	if w.wrap {
+		rules.WrapSize = w.wrapSize
+	}
+	return rules
+}
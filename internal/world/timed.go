@@ -0,0 +1,287 @@
+package world
+
+import (
+	"fmt"
+	. "maze/internal/global"
+	"strconv"
+	"strings"
+)
+
+// Клетки, которые меняются со временем. Время идёт тактами: за такт
+// проходится одна клетка или пропускается ход
+const (
+	Gate     = '#' // ворота, расписание задаёт секция [gates]
+	GateOpen = '/' // открытые ворота при воспроизведении
+	Guard    = '&' // охранник при воспроизведении, пути задаёт секция [guards]
+
+	gatesSection  = "gates"
+	guardsSection = "guards"
+)
+
+// gate Расписание ворот: открыты open тактов, затем закрыты closed тактов;
+// offset сдвигает расписание относительно нулевого такта
+type gate struct {
+	open, closed, offset int
+}
+
+func (g gate) isOpen(t int) bool {
+	return (t+g.offset)%(g.open+g.closed) < g.open
+}
+
+// guard Охранник обходит клетки по кругу, по клетке за такт
+type guard struct {
+	waypoints PointList // точки пути из секции [guards]
+	cycle     PointList // позиция охранника на каждом такте цикла
+}
+
+func (g guard) at(t int) PointOnMap {
+	return g.cycle[t%len(g.cycle)]
+}
+
+// applyGates читает расписания ворот из секции [gates], строками вида
+// "x,y open closed [offset]". Расписание нужно каждой клетке ворот
+func (w *World) applyGates(lines []string) error {
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 3 && len(fields) != 4 {
+			return fmt.Errorf("bad gates line %q, expected \"x,y open closed [offset]\"", line)
+		}
+		p, err := w.parsePoint(fields[0])
+		if err != nil {
+			return fmt.Errorf("bad gates line %q: %w", line, err)
+		}
+		if w.GetPoint(p[0], p[1]) != Gate {
+			return fmt.Errorf("bad gates line %q: no gate '%c' at %v", line, Gate, p)
+		}
+		var g gate
+		for i, v := range []*int{&g.open, &g.closed, &g.offset}[:len(fields)-1] {
+			if *v, err = strconv.Atoi(fields[i+1]); err != nil || *v < 0 {
+				return fmt.Errorf("bad gates line %q, expected non-negative numbers", line)
+			}
+		}
+		if g.open+g.closed == 0 {
+			return fmt.Errorf("bad gates line %q, empty period", line)
+		}
+		w.gates[p] = g
+	}
+	for y := 0; y < w.height; y++ {
+		for x := 0; x < w.width; x++ {
+			if _, ok := w.gates[PointOnMap{x, y}]; w.GetPoint(x, y) == Gate && !ok {
+				return fmt.Errorf("gate '%c' at %v has no schedule in [%s]", Gate, PointOnMap{x, y}, gatesSection)
+			}
+		}
+	}
+	return nil
+}
+
+// applyGuards читает пути охранников из секции [guards], строками вида
+// "x,y x,y ...". Соседние точки пути лежат на одной прямой. Охранник
+// идёт от первой точки к последней и обратно, а если путь замкнут
+// (последняя точка совпадает с первой) - по кругу
+func (w *World) applyGuards(lines []string) error {
+	for _, line := range lines {
+		g := guard{}
+		for _, field := range strings.Fields(line) {
+			p, err := w.parsePoint(field)
+			if err != nil {
+				return fmt.Errorf("bad guards line %q: %w", line, err)
+			}
+			if v := w.GetPoint(p[0], p[1]); v == Wall || v == FloorSeparator {
+				return fmt.Errorf("bad guards line %q: wall at %v", line, p)
+			}
+			if n := len(g.waypoints); n > 0 && !w.isStraight(g.waypoints[n-1], p) {
+				return fmt.Errorf("bad guards line %q: %v and %v are not on one line", line, g.waypoints[n-1], p)
+			}
+			g.waypoints = append(g.waypoints, p)
+		}
+		if len(g.waypoints) == 0 {
+			continue
+		}
+
+		var path PointList
+		path = append(path, g.waypoints[0])
+		for i := 1; i < len(g.waypoints); i++ {
+			dir := w.directionOf(g.waypoints[i-1], g.waypoints[i])
+			for p := g.waypoints[i-1]; p != g.waypoints[i]; {
+				p = w.nextCell(p, dir)
+				path = append(path, p)
+			}
+		}
+		if n := len(path); n > 1 && path[0] == path[n-1] {
+			g.cycle = path[:n-1]
+		} else {
+			g.cycle = path
+			for i := n - 2; i > 0; i-- {
+				g.cycle = append(g.cycle, path[i])
+			}
+		}
+		w.guards = append(w.guards, g)
+	}
+	return nil
+}
+
+func (w *World) parsePoint(src string) (PointOnMap, error) {
+	pair := strings.Split(src, ",")
+	if len(pair) != 2 {
+		return PointOnMap{}, fmt.Errorf("bad point %q, expected \"x,y\"", src)
+	}
+	x, errX := strconv.Atoi(pair[0])
+	y, errY := strconv.Atoi(pair[1])
+	if errX != nil || errY != nil || !w.inBounds(x, y) {
+		return PointOnMap{}, fmt.Errorf("bad point %q", src)
+	}
+	return PointOnMap{x, y}, nil
+}
+
+// Gates возвращает строки секции [gates]
+func (w *World) Gates() []string {
+	var lines []string
+	for y := 0; y < w.height; y++ {
+		for x := 0; x < w.width; x++ {
+			if g, ok := w.gates[PointOnMap{x, y}]; ok {
+				lines = append(lines, fmt.Sprintf("%d,%d %d %d %d", x, y, g.open, g.closed, g.offset))
+			}
+		}
+	}
+	return lines
+}
+
+// Guards возвращает строки секции [guards]
+func (w *World) Guards() []string {
+	var lines []string
+	for _, g := range w.guards {
+		fields := make([]string, 0, len(g.waypoints))
+		for _, p := range g.waypoints {
+			fields = append(fields, fmt.Sprintf("%d,%d", p[0], p[1]))
+		}
+		lines = append(lines, strings.Join(fields, " "))
+	}
+	return lines
+}
+
+// IsTimed проверяет, что на карте есть ворота или охранники
+func (w *World) IsTimed() bool {
+	return len(w.gates) > 0 || len(w.guards) > 0
+}
+
+// Period возвращает число тактов, через которое все ворота и охранники
+// возвращаются в исходное состояние
+func (w *World) Period() int {
+	period := 1
+	for _, g := range w.gates {
+		period = lcm(period, g.open+g.closed)
+	}
+	for _, g := range w.guards {
+		period = lcm(period, len(g.cycle))
+	}
+	return period
+}
+
+// SetTime задаёт такт, на котором показываются ворота и охранники
+func (w *World) SetTime(t int) {
+	w.time = t
+}
+
+func (w *World) Time() int {
+	return w.time
+}
+
+// blockedAt возвращает причину, по которой клетка занята на такте `t`,
+// или пустую строку
+func (w *World) blockedAt(p PointOnMap, t int) string {
+	if g, ok := w.gates[p]; ok && !g.isOpen(t) {
+		return fmt.Sprintf("gate at %v is closed at t=%d", p, t)
+	}
+	for _, g := range w.guards {
+		if g.at(t) == p {
+			return fmt.Sprintf("guard at %v at t=%d", p, t)
+		}
+	}
+	return ""
+}
+
+// StepTime возвращает число тактов на перемещение: по такту на клетку (и
+// через край карты), ожидание на месте - один такт, телепорт - мгновенно
+func (w *World) StepTime(from, to PointOnMap) int {
+	switch {
+	case w.IsHop(from, to):
+		return 0
+	case from == to:
+		return 1
+	}
+	return w.Rules().StepCost(MetricCells, Direction{}, from, to)
+}
+
+// RouteTime возвращает число тактов на маршрут
+func (w *World) RouteTime(route *Route) int {
+	t := 0
+	items := route.GetItems()
+	for i := 1; i < len(items); i++ {
+		t += w.StepTime(items[i-1], items[i])
+	}
+	return t
+}
+
+// passAt проверяет перемещение, начатое на такте `t`: ни одна клетка на
+// пути не занята, когда мы в неё входим, и мы не меняемся местами с
+// охранником. Возвращает причину, если пройти нельзя
+func (w *World) passAt(from, to PointOnMap, t int) string {
+	if w.IsHop(from, to) {
+		return w.blockedAt(to, t)
+	}
+	if from == to {
+		return w.blockedAt(to, t+1)
+	}
+	dir := w.directionOf(from, to)
+	for p := from; p != to; t++ {
+		next := w.nextCell(p, dir)
+		if reason := w.blockedAt(next, t+1); reason != "" {
+			return reason
+		}
+		for _, g := range w.guards {
+			if g.at(t) == next && g.at(t+1) == p {
+				return fmt.Sprintf("guard at %v at t=%d", next, t)
+			}
+		}
+		p = next
+	}
+	return ""
+}
+
+// FindMovesAt возвращает перемещения из точки `[fromX, fromY]`, начатые на
+// такте `t`, с их длительностью в тактах. Ожидание на месте - тоже
+// перемещение. При модели rook идём по клетке, чтобы можно было
+// остановиться где угодно и переждать
+func (w *World) FindMovesAt(fromX, fromY, t int, targets PointList) []GeoPosition {
+
+	from := PointOnMap{fromX, fromY}
+	candidates := append(w.FindNextMoves(fromX, fromY, targets), GeoPosition{fromX, fromY})
+	if w.movement == MovementRook {
+		candidates = append(w.findSteps(fromX, fromY, w.lineDirections()), GeoPosition{fromX, fromY})
+	}
+
+	var moves []GeoPosition
+	for _, mov := range candidates {
+		to := PointOnMap{mov[0], mov[1]}
+		if w.passAt(from, to, t) == "" {
+			moves = append(moves, GeoPosition{to[0], to[1], w.StepTime(from, to)})
+		}
+	}
+	return moves
+}
+
+// TimeSteps разбивает маршрут на перемещения по одному такту
+func (w *World) TimeSteps(route Route) Route {
+	return w.unfold(route)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func lcm(a, b int) int {
+	return a / gcd(a, b) * b
+}
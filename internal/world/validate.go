@@ -0,0 +1,150 @@
+package world
+
+import (
+	"fmt"
+	. "maze/internal/global"
+	"slices"
+)
+
+// RouteError Ошибка проверки маршрута по карте: указывает сегмент маршрута
+// (пару соседних точек) и причину
+type RouteError struct {
+	Step   int        // номер точки маршрута, на которой найдена ошибка
+	From   PointOnMap // начало сегмента
+	To     PointOnMap // конец сегмента
+	Reason string
+}
+
+func (e *RouteError) Error() string {
+	return fmt.Sprintf("bad route segment %v -> %v, step: %d: %s", e.From, e.To, e.Step, e.Reason)
+}
+
+// ValidateRoute проверяет маршрут по карте: маршрут начинается в одном из стартов,
+// заканчивается в одном из выходов, не выходит за границы карты, а каждое перемещение
+// возможно в модели перемещения, не проходит сквозь стены и двери, ключи от которых
+// ещё не собраны, не срезает угол между стенами и не идёт против стрелок. Шаг в
+// соседнюю стену сносит её, пока не исчерпан запас сносов (см. SetBreaks). На
+// карте с воротами и охранниками маршрут проходится по тактам с нулевого:
+// повтор точки - ожидание на месте. При сквозных краях (см. SetWrap) шаг между
+// клетками на противоположных краях - перемещение через край. С ограниченным
+// топливом (см. SetFuel) его должно хватить на каждое перемещение
+func ValidateRoute(w *World, route *Route) error {
+
+	items := route.GetItems()
+	if len(items) == 0 {
+		return &RouteError{Reason: "empty route"}
+	}
+
+	if starts := w.GetStartPoints(); !slices.Contains(starts, items[0]) {
+		return &RouteError{
+			From:   items[0],
+			To:     items[0],
+			Reason: fmt.Sprintf("route does not begin at start %v", starts),
+		}
+	}
+
+	defer w.OpenDoors(w.openDoors)
+	var keys KeySet
+	var broken PointList
+	t := 0
+	fuel := w.fuel
+	defer func() {
+		for _, p := range broken {
+			w.SetPoint(p[0], p[1], Wall)
+		}
+	}()
+	for i := 1; i < len(items); i++ {
+		w.OpenDoors(keys)
+		isBreak := len(broken) < w.breaks && w.isBreak(items[i-1], items[i])
+		if isBreak {
+			// стену сносим на время проверки, дальше по ней можно ходить
+			w.SetPoint(items[i][0], items[i][1], BrokenWall)
+			broken = append(broken, items[i])
+		}
+		if err := w.validateSegment(items[i-1], items[i]); err != nil {
+			err.Step = i
+			return err
+		}
+		if w.movement == MovementSlide && !isBreak && !w.IsHop(items[i-1], items[i]) && !w.isSlideStop(items[i-1], items[i]) {
+			return &RouteError{Step: i, From: items[i-1], To: items[i], Reason: "slide stops before a wall"}
+		}
+		if w.IsTimed() {
+			if reason := w.passAt(items[i-1], items[i], t); reason != "" {
+				return &RouteError{Step: i, From: items[i-1], To: items[i], Reason: reason}
+			}
+			t += w.StepTime(items[i-1], items[i])
+		}
+		if fuel = w.FuelAfter(items[i-1], items[i], fuel); fuel < 0 {
+			return &RouteError{Step: i, From: items[i-1], To: items[i], Reason: "out of fuel"}
+		}
+		for _, key := range w.KeysOn(items[i-1], items[i]) {
+			keys = keys.With(key)
+		}
+	}
+
+	last := items[len(items)-1]
+	if exits := w.GetExitPoints(); !slices.Contains(exits, last) {
+		return &RouteError{
+			Step:   len(items) - 1,
+			From:   last,
+			To:     last,
+			Reason: fmt.Sprintf("route does not end at exit %v", exits),
+		}
+	}
+	return nil
+}
+
+func (w *World) validateSegment(from, to PointOnMap) *RouteError {
+
+	for _, p := range []PointOnMap{from, to} {
+		if !w.inBounds(p[0], p[1]) {
+			return &RouteError{From: from, To: to, Reason: fmt.Sprintf("point %v is out of map", p)}
+		}
+	}
+
+	if w.IsHop(from, to) {
+		return nil
+	}
+
+	legal := w.isLegal(from, to)
+	if _, cells, ok := w.wrapMove(from, to); ok {
+		// через край карты пошаговые модели уходят только на соседнюю клетку
+		legal = w.movement != MovementStep && w.movement != MovementKing || cells == 1
+	}
+	if !legal && from != to {
+		if w.hex && !w.isStraight(from, to) {
+			return &RouteError{From: from, To: to, Reason: "move is off hex lines"}
+		}
+		if from[0] != to[0] && from[1] != to[1] && w.movement != MovementKing && !w.hex {
+			return &RouteError{From: from, To: to, Reason: "diagonal move"}
+		}
+		return &RouteError{From: from, To: to, Reason: fmt.Sprintf("move is too long for %s movement", w.movement)}
+	}
+
+	dir := w.directionOf(from, to)
+	for p := from; p != to; {
+		next := w.nextCell(p, dir)
+		if !w.moveablePoint(next[0], next[1]) {
+			if v := w.GetPoint(next[0], next[1]); IsDoor(v) {
+				return &RouteError{From: from, To: to, Reason: fmt.Sprintf("door %c at %v is locked", v, next)}
+			}
+			return &RouteError{From: from, To: to, Reason: fmt.Sprintf("wall at %v", next)}
+		}
+		if !w.canEnter(p, next, dir) {
+			arrow := next
+			if IsArrow(w.GetPoint(p[0], p[1])) && arrowDirections[w.GetPoint(p[0], p[1])] != dir {
+				arrow = p
+			}
+			return &RouteError{From: from, To: to, Reason: fmt.Sprintf("one-way cell at %v", arrow)}
+		}
+		if w.cutsCorner(p[0], p[1], dir[0], dir[1]) {
+			return &RouteError{From: from, To: to, Reason: fmt.Sprintf("corner cut at %v", next)}
+		}
+		p = next
+	}
+	return nil
+}
+
+func (w *World) inBounds(x, y int) bool {
+	return y < w.height && x < w.width && x >= 0 && y >= 0
+}
@@ -0,0 +1,169 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+	. "maze/internal/global"
+	"slices"
+	"testing"
+)
+
+func TestValidateRoute(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwwww
+		w@  wQw
+		w w   w
+		w    Qw
+		wwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		in         string
+		isPositive bool
+		step       int
+	}
+
+	testCases := []testCase{
+		{"[1 1] [1 3] [5 3]", true, 0},
+		{"[1 1] [3 1] [3 2] [5 2] [5 3]", true, 0},
+		{"[]", false, 0},
+		{"[2 1] [3 1] [3 2] [5 2] [5 3]", false, 0}, // не со старта
+		{"[1 1] [3 1] [3 2] [5 2] [5 1]", true, 0},  // до другого выхода
+		{"[1 1] [1 3] [4 3]", false, 2},             // не до выхода
+		{"[1 1] [5 1] [5 3]", false, 1},             // сквозь стену
+		{"[1 1] [1 3] [5 3] [5 9] [5 3]", false, 3}, // за границу карты
+		{"[1 1] [3 1] [5 3]", false, 2},             // по диагонали
+	}
+
+	for _, tc := range testCases {
+		t.Run("ValidateRoute()", func(t *testing.T) {
+			err := ValidateRoute(w, (&Route{}).Unserialize(tc.in))
+			if tc.isPositive != (err == nil) {
+				t.Fatalf("Failure on %s: %v", tc.in, err)
+			}
+			var routeErr *RouteError
+			if err != nil && (!errors.As(err, &routeErr) || routeErr.Step != tc.step) {
+				t.Errorf("Failure on %s: expected step %d, got %v", tc.in, tc.step, err)
+			}
+		})
+	}
+}
+
+func TestValidateRoute_Doors(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwwww
+		w@ A Qw
+		w wwwww
+		w   a w
+		wwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		in         string
+		isPositive bool
+		keys       string
+	}{
+		{"[1 1] [5 1]", false, ""},                         // дверь закрыта
+		{"[1 1] [1 3] [4 3] [1 3] [1 1] [5 1]", true, "a"}, // сначала ключ
+		{"[1 1] [1 3] [5 3] [1 3] [1 1] [5 1]", true, "a"}, // ключ по пути
+		{"[1 1] [1 3] [3 3] [1 3] [1 1] [5 1]", false, ""}, // мимо ключа
+	}
+
+	for _, tc := range testCases {
+		t.Run("ValidateRoute()", func(t *testing.T) {
+			route := (&Route{}).Unserialize(tc.in)
+			if err := ValidateRoute(w, route); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %s: %v", tc.in, err)
+			}
+			if keys := w.CollectKeys(route); keys != tc.keys {
+				t.Errorf("Failure on %s: expected keys %q, got %q", tc.in, tc.keys, keys)
+			}
+		})
+	}
+}
+
+func TestValidateRoute_Breaks(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwwwwww
+		w@  w w Q
+		w wwwwwww
+		w       w
+		wwwwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		in         string
+		breaks     int
+		isPositive bool
+		broken     string
+	}{
+		{"[1 1] [4 1] [6 1] [8 1]", 0, false, "[(4,1) (6,1)]"},
+		{"[1 1] [4 1] [6 1] [8 1]", 1, false, "[(4,1) (6,1)]"}, // сносов не хватает
+		{"[1 1] [4 1] [6 1] [8 1]", 2, true, "[(4,1) (6,1)]"},
+		{"[1 1] [4 1] [8 1]", 2, false, "[(4,1)]"}, // снести можно только стену, в которую упёрлись
+		{"[1 1] [4 1] [3 1] [4 1] [6 1] [8 1]", 2, true, "[(4,1) (6,1)]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run("ValidateRoute()", func(t *testing.T) {
+			w.SetBreaks(tc.breaks)
+			route := (&Route{}).Unserialize(tc.in)
+			if err := ValidateRoute(w, route); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %s with %d breaks: %v", tc.in, tc.breaks, err)
+			}
+			if broken := fmt.Sprint(w.BrokenWalls(route)); broken != tc.broken {
+				t.Errorf("Failure on %s: expected broken walls %s, got %s", tc.in, tc.broken, broken)
+			}
+		})
+	}
+}
+
+func TestValidateRoute_Fuel(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwwwwwwwwww
+		w@    F    Qw
+		wwwwwwwwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsDoor(Refuel) {
+		t.Errorf("Failure: refuel %c is not a door", Refuel)
+	}
+	w.SetFuel(5)
+
+	testCases := []struct {
+		in         string
+		isPositive bool
+		fuel       []int
+	}{
+		{"[1 1] [11 1]", true, []int{5, 0}},          // заправка на ходу
+		{"[1 1] [6 1] [11 1]", true, []int{5, 5, 0}}, // приехали с пустым баком
+		{"[1 1] [4 1] [1 1] [11 1]", false, []int{5, 2, -1, -1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run("ValidateRoute()", func(t *testing.T) {
+			route := (&Route{}).Unserialize(tc.in)
+			if err := ValidateRoute(w, route); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %s: %v", tc.in, err)
+			}
+			if fuel := w.RouteFuel(route); !slices.Equal(fuel, tc.fuel) {
+				t.Errorf("Failure on %s: expected fuel %v, got %v", tc.in, tc.fuel, fuel)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package world
+
+import (
+	. "maze/internal/global"
+	"slices"
+)
+
+// BrokenWall Снесённая стена: проходима, на карте отмечается отдельно
+const BrokenWall = '%'
+
+// SetBreaks задаёт, сколько стен можно снести на маршруте
+func (w *World) SetBreaks(k int) {
+	w.breaks = k
+}
+
+func (w *World) Breaks() int {
+	return w.breaks
+}
+
+// IsWall проверяет, что в точке стоит стена (и её можно снести)
+func (w *World) IsWall(p PointOnMap) bool {
+	return w.inBounds(p[0], p[1]) && w.GetPoint(p[0], p[1]) == Wall
+}
+
+// FindBreaks возвращает стены, которые можно снести, дойдя до них по прямой
+// из точки `[fromX, fromY]`, не пересекая края карты. При пошаговых моделях
+// перемещения - только соседние по вертикали и горизонтали стены
+func (w *World) FindBreaks(fromX, fromY int) []GeoPosition {
+	var moves []GeoPosition
+	from := PointOnMap{fromX, fromY}
+	for _, dir := range w.lineDirections() {
+		p := from
+		for w.movement != MovementStep && w.movement != MovementKing && w.canEnter(p, w.nextCell(p, dir), dir) &&
+			w.nextCell(p, dir) != from {
+			p = w.nextCell(p, dir)
+		}
+		if to := w.nextCell(p, dir); w.IsWall(to) && w.directionOf(from, to) == dir {
+			moves = append(moves, GeoPosition{to[0], to[1], w.SlideCost(from, to)})
+		}
+	}
+	return moves
+}
+
+// isBreak проверяет, что перемещение по прямой упирается в стену и сносит её
+func (w *World) isBreak(from, to PointOnMap) bool {
+	return w.IsWall(to) && !w.IsHop(from, to) && w.isStraight(from, to)
+}
+
+// BreakWalls сносит стены: клетки становятся проходимыми и отмечаются на
+// карте символом BrokenWall
+func (w *World) BreakWalls(points PointList) {
+	for _, p := range points {
+		if w.IsWall(p) {
+			w.SetPoint(p[0], p[1], BrokenWall)
+			w.broken[p] = true
+		}
+	}
+}
+
+// BrokenWalls возвращает стены, снесённые на маршруте, в порядке сноса
+func (w *World) BrokenWalls(route *Route) PointList {
+	var walls PointList
+	for _, p := range route.GetItems() {
+		if w.IsWall(p) && !slices.Contains(walls, p) {
+			walls = append(walls, p)
+		}
+	}
+	return walls
+}
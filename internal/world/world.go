@@ -4,7 +4,11 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
+	. "maze/internal/global"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -16,17 +20,49 @@ const (
 	Trace       = '.'
 	RouteNode   = '*'
 	FrameBorder = '+'
+	Mud         = '~'
+	Sand        = ':'
+	Road        = '='
 )
 
+// Стоимость прохода клетки по умолчанию. Переопределяется секцией карты
+// [legend], строками вида "~ 7" или "floor 3"
+const (
+	defaultFloorCost = 2
+	floorKeyword     = "floor"
+	legendSection    = "legend"
+)
+
+var defaultCosts = map[byte]int{
+	Road: 1,
+	Sand: 3,
+	Mud:  5,
+}
+
 type (
-	GeoPosition [3]int // x,y,distance
+	GeoPosition [3]int // x,y,cost
 	geo2D       [][]byte
 	World       struct {
 		geoMap         geo2D
-		width, height  int // map size
-		startX, startY int // initial position
-		exitX, exitY   int // target position
-		posX, posY     int // last position
+		width, height  int           // map size
+		startX, startY int           // initial position
+		starts         []GeoPosition // all start positions
+		exits          []GeoPosition // target positions
+		posX, posY     int           // last position
+		costs          map[byte]int
+		floorCost      int
+		openDoors      KeySet                    // двери, которые считаются открытыми
+		portals        map[PointOnMap]PointOnMap // телепорт и его пара
+		movement       Movement
+		breaks         int           // сколько стен можно снести
+		broken         PointRegistry // снесённые стены
+		gates          map[PointOnMap]gate
+		guards         []guard
+		time           int   // такт, на котором показываются ворота и охранники
+		floors         []int // первая строка каждого этажа
+		wrap           bool  // сквозные края карты
+		hex            bool  // шестиугольная сетка
+		fuel           int   // объём бака, ноль - топливо не ограничено
 	}
 )
 
@@ -35,19 +71,44 @@ func (p GeoPosition) ToArray() [2]int {
 }
 
 func Construct(text string) (*World, error) {
-	m2d := loadFromText(text)
+	mapText, sections := splitSections(text)
+	m2d := loadFromText(mapText)
 	if len(m2d) < 1 {
 		return nil, errors.New("bad data or constructor failed")
 	}
-	return construct(m2d), nil
+	w := construct(m2d)
+	if err := w.findPortals(); err != nil {
+		return nil, err
+	}
+	if err := w.findStairs(); err != nil {
+		return nil, err
+	}
+	if err := w.applyLegend(sections[legendSection]); err != nil {
+		return nil, err
+	}
+	if err := w.applyGrid(sections[gridSection]); err != nil {
+		return nil, err
+	}
+	if err := w.applyGates(sections[gatesSection]); err != nil {
+		return nil, err
+	}
+	if err := w.applyGuards(sections[guardsSection]); err != nil {
+		return nil, err
+	}
+	return w, nil
 }
 
 func construct(m2d geo2D) *World {
 
 	w := World{
-		width:  len(m2d[0]),
-		height: len(m2d),
-		geoMap: m2d,
+		width:     len(m2d[0]),
+		height:    len(m2d),
+		geoMap:    m2d,
+		costs:     maps.Clone(defaultCosts),
+		floorCost: defaultFloorCost,
+		movement:  MovementRook,
+		broken:    PointRegistry{},
+		gates:     map[PointOnMap]gate{},
 	}
 
 	for x := 0; x < w.width; x++ {
@@ -55,17 +116,121 @@ func construct(m2d geo2D) *World {
 			v := &m2d[y][x]
 			switch *v {
 			case Exit:
-				w.exitX, w.exitY = x, y
+				w.exits = append(w.exits, GeoPosition{x, y})
 			case Me:
-				w.startX, w.startY = x, y
+				w.starts = append(w.starts, GeoPosition{x, y})
 				*v = Space // освобождаем место, где мы стоим
 			}
 		}
 	}
+	// старты и выходы нумеруются по строкам, слева направо
+	slices.SortFunc(w.starts, comparePositions)
+	slices.SortFunc(w.exits, comparePositions)
+	if len(w.starts) > 0 {
+		w.startX, w.startY = w.starts[0][0], w.starts[0][1]
+	}
 	w.posX, w.posY = w.startX, w.startY
+	w.findFloors()
 	return &w
 }
 
+func comparePositions(a, b GeoPosition) int {
+	if a[1] != b[1] {
+		return a[1] - b[1]
+	}
+	return a[0] - b[0]
+}
+
+// splitSections отделяет карту от именованных секций. Секция начинается
+// строкой "[name]" и продолжается до следующей секции; пустые строки
+// и строки-комментарии "#" в секциях пропускаются
+func splitSections(text string) (string, map[string][]string) {
+	var mapLines []string
+	var sections = map[string][]string{}
+	var current = ""
+	var sc = bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.ToLower(strings.Trim(line, "[]"))
+			sections[current] = sections[current][:0:0]
+			continue
+		}
+		if current == "" {
+			mapLines = append(mapLines, sc.Text())
+		} else if line != "" && !strings.HasPrefix(line, "#") {
+			sections[current] = append(sections[current], line)
+		}
+	}
+	return strings.Join(mapLines, "\n"), sections
+}
+
+// applyLegend применяет стоимости клеток из секции [legend]
+func (w *World) applyLegend(lines []string) error {
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return fmt.Errorf("bad legend line %q, expected \"<symbol> <cost>\"", line)
+		}
+		cost, err := strconv.Atoi(fields[1])
+		if err != nil || cost < 1 {
+			return fmt.Errorf("bad cost in legend line %q, expected positive number", line)
+		}
+		switch symbol := fields[0]; {
+		case symbol == floorKeyword:
+			w.floorCost = cost
+		case len(symbol) == 1 && symbol[0] != Wall:
+			w.costs[symbol[0]] = cost
+		default:
+			return fmt.Errorf("bad symbol in legend line %q", line)
+		}
+	}
+	return nil
+}
+
+// Legend возвращает строки секции [legend], которые отличаются от стоимостей
+// по умолчанию
+func (w *World) Legend() []string {
+	var lines []string
+	if w.floorCost != defaultFloorCost {
+		lines = append(lines, fmt.Sprintf("%s %d", floorKeyword, w.floorCost))
+	}
+	symbols := slices.Sorted(maps.Keys(w.costs))
+	for _, symbol := range symbols {
+		if cost := w.costs[symbol]; defaultCosts[symbol] != cost {
+			lines = append(lines, fmt.Sprintf("%c %d", symbol, cost))
+		}
+	}
+	return lines
+}
+
+// CellCost возвращает стоимость прохода клетки
+func (w *World) CellCost(x, y int) int {
+	if cost, ok := w.costs[w.GetPoint(x, y)]; ok {
+		return cost
+	}
+	return w.floorCost
+}
+
+// SlideCost возвращает стоимость перемещения по прямой: сумму стоимостей
+// клеток, в которые мы входим (клетка старта не считается)
+func (w *World) SlideCost(from, to PointOnMap) int {
+	if w.IsHop(from, to) {
+		return 0
+	}
+	if w.hex && !w.isStraight(from, to) {
+		// такого перемещения нет, но стоимость должна быть конечной
+		return HexDistance(from, to) * w.floorCost
+	}
+	cost := 0
+	dir := w.directionOf(from, to)
+	for p := from; p != to; {
+		p = w.nextCell(p, dir)
+		cost += w.CellCost(p[0], p[1])
+	}
+	return cost
+}
+
 // loadFromText
 //
 // Example:
@@ -85,6 +250,10 @@ func loadFromText(mapAsString string) geo2D {
 	for sc.Scan() {
 		line := strings.TrimSpace(sc.Text())
 		if line != "" {
+			if isSeparatorLine(line) {
+				lines = append(lines, string(FloorSeparator))
+				continue
+			}
 			if l := len(line); l > maxRowLen {
 				maxRowLen = l
 			}
@@ -98,31 +267,109 @@ func loadFromText(mapAsString string) geo2D {
 		for i, c := range line {
 			row[i] = byte(c)
 		}
+		if line == string(FloorSeparator) {
+			// разделитель этажей непроходим по всей ширине карты
+			for i := range row {
+				row[i] = FloorSeparator
+			}
+		}
 		rows[n] = row
 	}
 	return rows
 }
 
+// Check проверяет, что на карте есть хотя бы один старт и один выход
+func (w *World) Check() error {
+	if len(w.starts) == 0 {
+		return fmt.Errorf("no start '%c' found", Me)
+	}
+	if len(w.exits) == 0 {
+		return fmt.Errorf("no exit '%c' found", Exit)
+	}
+	return nil
+}
+
+// Lines возвращает карту в текстовом формате (со стартами и выходами)
+func (w *World) Lines() []string {
+	lines := make([]string, w.height)
+	for y := 0; y < w.height; y++ {
+		row := make([]byte, w.width)
+		for x := 0; x < w.width; x++ {
+			row[x] = w.GetPoint(x, y)
+			if row[x] == 0 {
+				row[x] = Space
+			}
+		}
+		lines[y] = string(row)
+	}
+	for _, start := range w.starts {
+		x, y := start[0], start[1]
+		lines[y] = lines[y][:x] + string(Me) + lines[y][x+1:]
+	}
+	return lines
+}
+
 func (w *World) GetSizes() (int, int) {
 	return w.width, w.height
 }
 
+// GetStart возвращает текущий старт (первый по строкам, слева направо)
 func (w *World) GetStart() GeoPosition {
 	return GeoPosition{w.startX, w.startY}
 }
 
+// GetStarts возвращает все старты, номер старта - индекс в списке
+func (w *World) GetStarts() []GeoPosition {
+	return slices.Clone(w.starts)
+}
+
+// GetStartPoints возвращает все старты как список точек
+func (w *World) GetStartPoints() PointList {
+	points := make(PointList, len(w.starts))
+	for i, start := range w.starts {
+		points[i] = start.ToArray()
+	}
+	return points
+}
+
+// GetExit возвращает первый выход (по строкам, слева направо)
 func (w *World) GetExit() GeoPosition {
-	return GeoPosition{w.exitX, w.exitY}
+	if len(w.exits) == 0 {
+		return GeoPosition{}
+	}
+	return w.exits[0]
+}
+
+// GetExits возвращает все выходы
+func (w *World) GetExits() []GeoPosition {
+	return slices.Clone(w.exits)
+}
+
+// GetExitPoints возвращает все выходы как список точек
+func (w *World) GetExitPoints() PointList {
+	points := make(PointList, len(w.exits))
+	for i, exit := range w.exits {
+		points[i] = exit.ToArray()
+	}
+	return points
 }
 
+// SetStart делает точку единственным стартом
 func (w *World) SetStart(start GeoPosition) {
+	w.starts = []GeoPosition{{start[0], start[1]}}
 	w.startX, w.startY = start[0], start[1]
 	w.posX, w.posY = w.startX, w.startY
 }
 
+// SetPosition переносит текущую позицию, старты не меняются
+func (w *World) SetPosition(pos GeoPosition) {
+	w.posX, w.posY = pos[0], pos[1]
+}
+
+// SetExit делает точку единственным выходом
 func (w *World) SetExit(exit GeoPosition) {
-	w.exitX, w.exitY = exit[0], exit[1]
-	w.SetPoint(w.exitX, w.exitY, Exit)
+	w.exits = []GeoPosition{{exit[0], exit[1]}}
+	w.SetPoint(exit[0], exit[1], Exit)
 }
 
 func (w *World) SetPoint(x, y int, value byte) {
@@ -134,56 +381,78 @@ func (w *World) GetPoint(x, y int) byte {
 }
 
 func (w *World) getPointAsSymbol(x, y int) string {
+	p := PointOnMap{x, y}
+	for _, g := range w.guards {
+		if g.at(w.time) == p {
+			return string(Guard)
+		}
+	}
+	if g, ok := w.gates[p]; ok && w.GetPoint(x, y) == Gate && g.isOpen(w.time) {
+		return string(GateOpen)
+	}
 	return string(w.GetPoint(x, y))
 }
 
 func (w *World) moveablePoint(x, y int) bool {
-	if y < w.height && x < w.width && x >= 0 && y >= 0 {
-		return w.GetPoint(x, y) != Wall
+	if w.inBounds(x, y) {
+		v := w.GetPoint(x, y)
+		if IsDoor(v) {
+			return w.openDoors.Has(KeyOf(v))
+		}
+		return v != Wall && v != FloorSeparator
 	}
 	return false
 }
 
 func (w *World) Move(x, y int, traceEnabled bool) error {
 
-	if traceEnabled {
-		if w.posY == y {
-			from, to := w.posX, x
-			if from > to {
-				from, to = to, from
-			}
-			for i := from; i < to; i++ {
-				if w.GetPoint(i, y) == RouteNode {
-					continue
-				}
-				w.SetPoint(i, y, Trace)
-			}
-		} else if w.posX == x {
-			from, to := w.posY, y
-			if from > to {
-				from, to = to, from
-			}
-			for i := from; i < to; i++ {
-				if w.GetPoint(x, i) == RouteNode {
-					continue
-				}
-				w.SetPoint(x, i, Trace)
+	// прыжок через телепорт не оставляет следа между парой телепортов,
+	// шаг по диагонали - тоже: между соседними клетками следа нет. След
+	// перемещения через край карты остаётся по обе стороны края
+	from, to := PointOnMap{w.posX, w.posY}, PointOnMap{x, y}
+	hop := w.IsHop(from, to) || w.movement == MovementKing && w.movement.IsLegal(from, to)
+
+	if traceEnabled && !hop && from != to {
+		if !w.isStraight(from, to) {
+			if w.hex {
+				return fmt.Errorf("stop moving off hex lines [%d,%d] -> [%d,%d]", x, y, w.posX, w.posY)
 			}
-		} else {
 			msg := "stop diagonal moving [%d,%d] -> [%d,%d]"
 			return fmt.Errorf(msg, x, y, w.posX, w.posY)
 		}
+		dir := w.directionOf(from, to)
+		for p := w.nextCell(from, dir); p != to; p = w.nextCell(p, dir) {
+			if v := w.GetPoint(p[0], p[1]); v != RouteNode && v != BrokenWall {
+				w.SetPoint(p[0], p[1], Trace)
+			}
+		}
+	}
+	if w.broken[PointOnMap{w.posX, w.posY}] {
+		w.SetPoint(w.posX, w.posY, BrokenWall)
+	} else {
+		w.SetPoint(w.posX, w.posY, RouteNode)
 	}
-	w.SetPoint(w.posX, w.posY, RouteNode)
 	w.SetPoint(x, y, Me)
 	w.posX, w.posY = x, y
 	return nil
 }
 
 // FindNextMoves возвращает возможные позиции для очередного перемещения
-// из точки заданной `[fromX, fromY]`
-// TODO: distance and maxFn()
-func (w *World) FindNextMoves(fromX, fromY, exitX, exitY int) []GeoPosition {
+// из точки заданной `[fromX, fromY]` вместе со стоимостью перемещения.
+// Цели и телепорты, видимые по прямой, всегда попадают в перемещения,
+// а из телепорта можно перейти в его пару. При сквозных краях прямая
+// продолжается за краем карты.
+// Позиции зависят от модели перемещения (см. SetMovement) и формы клеток
+func (w *World) FindNextMoves(fromX, fromY int, targets PointList) []GeoPosition {
+
+	switch {
+	case w.movement == MovementSlide:
+		return w.findSlides(fromX, fromY, targets)
+	case w.movement == MovementStep, w.movement == MovementKing:
+		return w.findSteps(fromX, fromY, w.stepDirections())
+	case w.hex, w.wrap:
+		return w.findLines(fromX, fromY, targets)
+	}
 
 	//w.posX, w.posY = fromX, fromY
 
@@ -198,20 +467,25 @@ func (w *World) FindNextMoves(fromX, fromY, exitX, exitY int) []GeoPosition {
 	//fmt.Println(horizontals)
 	//fmt.Println(verticals)
 	var moves []GeoPosition
+	from := PointOnMap{fromX, fromY}
 
-	if w.canMoveTo(fromX, fromY, exitX, exitY) {
-		// добавим саму точку выхода в очередное возможное перемещение
-		distance := func() int {
-			var distance int
-			if fromX == exitX {
-				distance = int(math.Abs(float64(fromY - exitY)))
-			} else {
-				distance = int(math.Abs(float64(fromX - exitX)))
-			}
-			return distance
-		}()
-		mov := GeoPosition{exitX, exitY, distance}
-		moves = append(moves, mov)
+	if twin, ok := w.Twin(from); ok {
+		// из телепорта можно сразу перейти в его пару
+		moves = append(moves, GeoPosition{twin[0], twin[1], 0})
+	}
+
+	// телепорты, видимые по прямой, - такие же узлы, как и цели
+	for _, target := range append(w.GetPortalPoints(), targets...) {
+		exitX, exitY := target[0], target[1]
+		if target == from {
+			continue
+		}
+		if w.canMoveTo(fromX, fromY, exitX, exitY) {
+			// добавим саму точку выхода в очередное возможное перемещение
+			cost := w.SlideCost(from, PointOnMap{exitX, exitY})
+			mov := GeoPosition{exitX, exitY, cost}
+			moves = append(moves, mov)
+		}
 	}
 
 	for _, h := range horizontals {
@@ -227,7 +501,7 @@ func (w *World) FindNextMoves(fromX, fromY, exitX, exitY int) []GeoPosition {
 				continue
 			}
 			if yFrom <= y && y <= yTo && xFrom <= x && x <= xTo {
-				mov := GeoPosition{x, y, maxFn(h[3], v[3])}
+				mov := GeoPosition{x, y, w.SlideCost(from, PointOnMap{x, y})}
 				moves = append(moves, mov)
 			}
 		}
@@ -269,11 +543,11 @@ func (w *World) findHorizontals(fromX, fromY int) (axes [][4]int) {
 // на которое можно переместиться из точки `[fromX, fromY]`
 func (w *World) getVerticalRange(fromX, fromY int) (min, max int) {
 	min, max = fromY, fromY
-	for i := fromY - 1; w.moveablePoint(fromX, i); i-- {
-		min = i
+	for i := fromY; w.canStep(fromX, i, 0, -1); i-- {
+		min = i - 1
 	}
-	for i := fromY + 1; w.moveablePoint(fromX, i); i++ {
-		max = i
+	for i := fromY; w.canStep(fromX, i, 0, 1); i++ {
+		max = i + 1
 	}
 	return
 }
@@ -282,11 +556,11 @@ func (w *World) getVerticalRange(fromX, fromY int) (min, max int) {
 // на которые можно переместиться из точки `[fromX, fromY]`
 func (w *World) getHorizontalRange(fromX, fromY int) (min, max int) {
 	min, max = fromX, fromX
-	for i := fromX - 1; w.moveablePoint(i, fromY); i-- {
-		min = i
+	for i := fromX; w.canStep(i, fromY, -1, 0); i-- {
+		min = i - 1
 	}
-	for i := fromX + 1; w.moveablePoint(i, fromY); i++ {
-		max = i
+	for i := fromX; w.canStep(i, fromY, 1, 0); i++ {
+		max = i + 1
 	}
 	return
 }
@@ -301,12 +575,9 @@ func (w *World) canMoveTo(fromX, fromY, toX, toY int) bool {
 }
 
 func (w *World) canMoveToX(fromX, fromY, toX int) bool {
-	from, to := fromX, toX
-	if from > to {
-		from, to = to, from
-	}
-	for x := from + 1; x < to; x++ {
-		if !w.moveablePoint(x, fromY) {
+	dx := sign(toX - fromX)
+	for x := fromX; x != toX; x += dx {
+		if !w.canStep(x, fromY, dx, 0) {
 			return false
 		}
 	}
@@ -314,23 +585,23 @@ func (w *World) canMoveToX(fromX, fromY, toX int) bool {
 }
 
 func (w *World) canMoveToY(fromX, fromY, toY int) bool {
-	from, to := fromY, toY
-	if from > to {
-		from, to = to, from
-	}
-	for y := from + 1; y < to; y++ {
-		if !w.moveablePoint(fromX, y) {
+	dy := sign(toY - fromY)
+	for y := fromY; y != toY; y += dy {
+		if !w.canStep(fromX, y, 0, dy) {
 			return false
 		}
 	}
 	return true
 }
 
-func maxFn(a, b int) int {
-	if a > b {
-		return a
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
 	}
-	return b
+	return 0
 }
 
 func PrintMe(w *World) {
@@ -344,11 +615,38 @@ func PrintMapOnly(w *World) {
 func PrintMap(w *World, mePosX, mePosY int) {
 
 	fmt.Printf("Map size: %dx%d\n", w.width, w.height)
-	fmt.Printf("Start position: [%d,%d]\n", w.startX, w.startY)
-	fmt.Printf("Exit position: [%d,%d]\n", w.exitX, w.exitY)
+	if len(w.starts) > 1 {
+		for i, start := range w.starts {
+			fmt.Printf("Start position #%d: [%d,%d]\n", i, start[0], start[1])
+		}
+	} else {
+		fmt.Printf("Start position: [%d,%d]\n", w.startX, w.startY)
+	}
+	for _, exit := range w.exits {
+		fmt.Printf("Exit position: [%d,%d]\n", exit[0], exit[1])
+	}
+	if w.IsTimed() {
+		fmt.Printf("Time: %d\n", w.time)
+	}
+	if w.GetFloors() > 1 && w.inBounds(mePosX, mePosY) {
+		fmt.Printf("Floor: %d\n", w.Floor(mePosY))
+	}
 	fmt.Println()
 
 	for y := 0; y < w.height; y++ {
+		if w.GetFloors() > 1 {
+			// этажи выводим по отдельности, вместо разделителя - номер этажа
+			if z := w.Floor(y); w.floors[z] == y {
+				fmt.Printf("Floor %d:\n", z)
+			} else if z+1 < len(w.floors) && w.floors[z+1] == y+1 {
+				fmt.Println()
+				continue
+			}
+		}
+		if w.hex && y%2 == 1 {
+			// нечётные строки шестиугольной сетки сдвинуты на полклетки
+			fmt.Print(" ")
+		}
 		for x := 0; x < w.width; x++ {
 			if x == mePosX && y == mePosY {
 				fmt.Print(" ", string(Me))
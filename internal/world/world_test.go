@@ -0,0 +1,658 @@
+package world
+
+import (
+	. "maze/internal/global"
+	"slices"
+	"testing"
+)
+
+func TestConstruct_Legend(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwww
+		w@~:=Q
+		wwwwww
+
+		[legend]
+		# comment
+		: 4
+		floor 7
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		from, to PointOnMap
+		cost     int
+	}
+
+	testCases := []testCase{
+		{PointOnMap{1, 1}, PointOnMap{1, 1}, 0},
+		{PointOnMap{1, 1}, PointOnMap{2, 1}, 5},             // mud по умолчанию
+		{PointOnMap{1, 1}, PointOnMap{5, 1}, 5 + 4 + 1 + 7}, // выход стоит как пол
+		{PointOnMap{5, 1}, PointOnMap{1, 1}, 1 + 4 + 5 + 7}, // обратно
+	}
+
+	for _, tc := range testCases {
+		t.Run("SlideCost()", func(t *testing.T) {
+			if result := w.SlideCost(tc.from, tc.to); result != tc.cost {
+				t.Errorf("Failure on %v -> %v: expected %d, got %d", tc.from, tc.to, tc.cost, result)
+			}
+		})
+	}
+
+	expected := []string{"floor 7", ": 4"}
+	if legend := w.Legend(); len(legend) != 2 || legend[0] != expected[0] || legend[1] != expected[1] {
+		t.Errorf("Failure: expected legend %q, got %q", expected, legend)
+	}
+}
+
+func TestConstruct_BadLegend(t *testing.T) {
+
+	for _, legend := range []string{"~", "~ 0", "~ x", "w 3", "ab 3"} {
+		t.Run("Construct()", func(t *testing.T) {
+			if _, err := Construct("w@ Qw\n[legend]\n" + legend); err == nil {
+				t.Errorf("Failure: legend %q accepted", legend)
+			}
+		})
+	}
+}
+
+func TestConstruct_Exits(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwww
+		w  wQw
+		wQ@ Qw
+		wwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := PointList{{4, 1}, {1, 2}, {4, 2}}
+	if exits := w.GetExitPoints(); !slices.Equal(exits, expected) {
+		t.Errorf("Failure: expected exits %v, got %v", expected, exits)
+	}
+	if exit := w.GetExit(); exit != (GeoPosition{4, 1}) {
+		t.Errorf("Failure: expected first exit [4,1], got %v", exit)
+	}
+
+	moves := w.FindNextMoves(2, 2, w.GetExitPoints())
+	for _, target := range []PointOnMap{{1, 2}, {4, 2}} {
+		found := false
+		for _, mov := range moves {
+			found = found || (PointOnMap{mov[0], mov[1]}) == target
+		}
+		if !found {
+			t.Errorf("Failure: exit %v is not in moves %v", target, moves)
+		}
+	}
+
+	if w, err := Construct("w@ w"); err != nil || w.Check() == nil {
+		t.Errorf("Failure: map without exit accepted")
+	}
+}
+
+func TestConstruct_Starts(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwww
+		w @wQw
+		w@  @w
+		wwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := PointList{{2, 1}, {1, 2}, {4, 2}}
+	if starts := w.GetStartPoints(); !slices.Equal(starts, expected) {
+		t.Errorf("Failure: expected starts %v, got %v", expected, starts)
+	}
+	if start := w.GetStart(); start != (GeoPosition{2, 1}) {
+		t.Errorf("Failure: expected first start [2,1], got %v", start)
+	}
+	if lines := w.Lines(); lines[1] != "w @wQw" || lines[2] != "w@  @w" {
+		t.Errorf("Failure: starts are lost in %q", lines)
+	}
+	if err := ValidateRoute(w, (&Route{}).Unserialize("[4 2] [4 1]")); err != nil {
+		t.Errorf("Failure: route from the last start: %v", err)
+	}
+}
+
+func TestConstruct_Portals(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwwww
+		w@ 1w1w
+		w w  Qw
+		wwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if twin, ok := w.Twin(PointOnMap{3, 1}); !ok || twin != (PointOnMap{5, 1}) {
+		t.Errorf("Failure: expected twin [5 1], got %v", twin)
+	}
+
+	found := false
+	for _, mov := range w.FindNextMoves(3, 1, w.GetExitPoints()) {
+		found = found || (PointOnMap{mov[0], mov[1]} == PointOnMap{5, 1} && mov[2] == 0)
+	}
+	if !found {
+		t.Errorf("Failure: twin is not in moves from portal")
+	}
+
+	route := (&Route{}).Unserialize("[1 1] [3 1] [5 1] [5 2]")
+	if err := ValidateRoute(w, route); err != nil {
+		t.Errorf("Failure: %v", err)
+	}
+	if cost := route.CostBy(w.Rules()); cost.Moves != 2 || cost.Cells != 3 {
+		t.Errorf("Failure: bad cost %v", cost)
+	}
+
+	for _, text := range []string{"w@1 Qw", "w@1 1Qw1w"} {
+		if _, err := Construct(text); err == nil {
+			t.Errorf("Failure: unpaired portals accepted in %q", text)
+		}
+	}
+}
+
+func TestConstruct_Arrows(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwwww
+		w@ > Qw
+		w wwwww
+		w  <  w
+		wwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		in         string
+		isPositive bool
+	}
+
+	testCases := []testCase{
+		{"[1 1] [5 1]", true},
+		{"[1 1] [1 3] [5 3]", false}, // против стрелки
+		{"[1 1] [3 1] [3 3]", false}, // поперёк стрелки
+	}
+
+	for _, tc := range testCases {
+		t.Run("ValidateRoute()", func(t *testing.T) {
+			if err := ValidateRoute(w, (&Route{}).Unserialize(tc.in)); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %s: %v", tc.in, err)
+			}
+		})
+	}
+
+	if x1, x2 := w.getHorizontalRange(1, 3); x1 != 1 || x2 != 2 {
+		t.Errorf("Failure: expected range [1, 2], got [%d, %d]", x1, x2)
+	}
+	w.Revert()
+	if v := w.GetPoint(3, 1); v != ArrowLeft {
+		t.Errorf("Failure: expected reverted arrow '<', got '%c'", v)
+	}
+	if x1, x2 := w.getHorizontalRange(1, 3); x1 != 1 || x2 != 5 {
+		t.Errorf("Failure: expected reverted range [1, 5], got [%d, %d]", x1, x2)
+	}
+}
+
+func TestFindNextMoves_Slide(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwwww
+		w@   ww
+		w  Q  w
+		w w   w
+		wwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetMovement(MovementSlide)
+
+	var moves PointList
+	for _, mov := range w.FindNextMoves(1, 1, w.GetExitPoints()) {
+		moves = append(moves, PointOnMap{mov[0], mov[1]})
+	}
+	moves.Sort()
+	expected := PointList{{1, 3}, {4, 1}}
+	if !slices.Equal(moves, expected) {
+		t.Errorf("Failure: expected slides %v, got %v", expected, moves)
+	}
+
+	type testCase struct {
+		in         string
+		isPositive bool
+	}
+
+	testCases := []testCase{
+		{"[1 1] [4 1] [4 3] [3 3] [3 2]", true},
+		{"[1 1] [4 1] [4 3] [3 3] [3 1] [3 2]", true}, // скольжение через выход
+		{"[1 1] [3 1] [3 2]", false},                  // остановка посреди льда
+	}
+
+	for _, tc := range testCases {
+		t.Run("ValidateRoute()", func(t *testing.T) {
+			if err := ValidateRoute(w, (&Route{}).Unserialize(tc.in)); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %s: %v", tc.in, err)
+			}
+		})
+	}
+}
+
+func TestFindNextMoves_King(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwww
+		w@w  w
+		ww  Qw
+		w    w
+		wwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		movement Movement
+		from     PointOnMap
+		expected PointList
+	}
+
+	testCases := []testCase{
+		{MovementStep, PointOnMap{1, 1}, nil},
+		{MovementKing, PointOnMap{1, 1}, nil}, // угол между двумя стенами не срезать
+		{MovementStep, PointOnMap{2, 2}, PointList{{2, 3}, {3, 2}}},
+		{MovementKing, PointOnMap{2, 2}, PointList{{1, 3}, {2, 3}, {3, 1}, {3, 2}, {3, 3}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run("FindNextMoves()", func(t *testing.T) {
+			w.SetMovement(tc.movement)
+			var moves PointList
+			for _, mov := range w.FindNextMoves(tc.from[0], tc.from[1], w.GetExitPoints()) {
+				moves = append(moves, PointOnMap{mov[0], mov[1]})
+			}
+			moves.Sort()
+			if !slices.Equal(moves, tc.expected) {
+				t.Errorf("Failure on %s %v: expected %v, got %v", tc.movement, tc.from, tc.expected, moves)
+			}
+		})
+	}
+
+	w, err = Construct(`
+		wwwwwww
+		w @w  w
+		w w   w
+		w    Qw
+		wwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type routeCase struct {
+		movement   Movement
+		in         string
+		isPositive bool
+	}
+
+	routeCases := []routeCase{
+		{MovementKing, "[2 1] [1 2] [2 3] [3 3] [4 3] [5 3]", true},
+		{MovementKing, "[2 1] [3 2] [4 2] [5 3]", false}, // срезан угол между стенами
+		{MovementKing, "[2 1] [1 2] [2 3] [5 3]", false}, // только на одну клетку
+		{MovementRook, "[2 1] [1 2] [2 3] [5 3]", false},
+		{MovementStep, "[2 1] [1 1] [1 2] [1 3] [2 3] [3 3] [4 3] [5 3]", true},
+		{MovementStep, "[2 1] [1 1] [1 3] [5 3]", false},
+	}
+
+	for _, tc := range routeCases {
+		t.Run("ValidateRoute()", func(t *testing.T) {
+			w.SetMovement(tc.movement)
+			if err := ValidateRoute(w, (&Route{}).Unserialize(tc.in)); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %s %s: %v", tc.movement, tc.in, err)
+			}
+		})
+	}
+
+	w.SetMovement(MovementStep)
+	unfolded := w.Unfold(*(&Route{}).Unserialize("[2 1] [1 1] [1 3] [5 3]"))
+	if expected := "[2 1] [1 1] [1 2] [1 3] [2 3] [3 3] [4 3] [5 3]"; unfolded.Serialize() != expected {
+		t.Errorf("Failure on Unfold(): expected %s, got %s", expected, unfolded.Serialize())
+	}
+}
+
+func TestConstruct_Timed(t *testing.T) {
+
+	testCases := []struct {
+		in         string
+		isPositive bool
+	}{
+		{"w@#Qw\n[gates]\n2,0 1 1", true},
+		{"w@#Qw\n[gates]\n2,0 1 1 3", true},
+		{"w@#Qw", false},                         // у ворот нет расписания
+		{"w@#Qw\n[gates]\n1,0 1 1", false},       // в клетке нет ворот
+		{"w@#Qw\n[gates]\n2,0 0 0", false},       // пустой период
+		{"w@ Qw\n[guards]\n2,0 3,0", true},       // туда и обратно
+		{"w@ Qw\n[guards]\n0,0 2,0", false},      // охранник в стене
+		{"w@ Q\nw  w\n[guards]\n1,1 2,0", false}, // точки не на одной прямой
+	}
+
+	for _, tc := range testCases {
+		t.Run("Construct()", func(t *testing.T) {
+			if _, err := Construct(tc.in); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %q: %v", tc.in, err)
+			}
+		})
+	}
+}
+
+func TestValidateRoute_Timed(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwwww
+		w@ #  Q
+		w     w
+		wwwwwww
+		[gates]
+		3,1 1 3
+		[guards]
+		5,1 5,2 4,2 4,1 5,1
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if period := w.Period(); period != 4 {
+		t.Errorf("Failure: expected period 4, got %d", period)
+	}
+
+	testCases := []struct {
+		in         string
+		isPositive bool
+	}{
+		{"[1 1] [6 1]", false},                   // ворота закрыты на такте 2
+		{"[1 1] [1 1] [1 1] [6 1]", true},        // переждали: ворота открыты на такте 4
+		{"[1 1] [1 1] [1 1] [1 1] [6 1]", false}, // опоздали к воротам
+		{"[1 1] [1 2] [5 2] [5 1] [6 1]", false}, // столкнулись с охранником
+		{"[1 1] [1 2] [3 2] [3 2] [3 2] [4 2] [4 1] [6 1]", false},
+		{"[1 1] [1 2] [3 2] [3 2] [3 2] [3 2] [4 2] [4 1] [6 1]", true}, // идём следом за охранником
+	}
+
+	for _, tc := range testCases {
+		t.Run("ValidateRoute()", func(t *testing.T) {
+			if err := ValidateRoute(w, (&Route{}).Unserialize(tc.in)); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %s: %v", tc.in, err)
+			}
+		})
+	}
+}
+
+func TestFloors(t *testing.T) {
+
+	w, err := Construct(`
+		w@ Uw
+		w   w
+		-----
+		wQ Dw
+		w   w
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if floors := w.GetFloors(); floors != 2 {
+		t.Fatalf("Failure: expected 2 floors, got %d", floors)
+	}
+	if p3 := w.To3D(PointOnMap{3, 3}); p3 != (PointOnMap3D{3, 0, 1}) {
+		t.Errorf("Failure on To3D(): expected (3,0,1), got %v", p3)
+	}
+	if p, ok := w.From3D(PointOnMap3D{3, 0, 1}); !ok || p != (PointOnMap{3, 3}) {
+		t.Errorf("Failure on From3D(): expected (3,3), got %v", p)
+	}
+	if _, ok := w.From3D(PointOnMap3D{3, 2, 0}); ok {
+		t.Errorf("Failure on From3D(): separator is not a floor cell")
+	}
+	if twin, ok := w.Twin(PointOnMap{3, 0}); !ok || twin != (PointOnMap{3, 3}) {
+		t.Errorf("Failure: expected stairs to (3,3), got %v", twin)
+	}
+
+	testCases := []struct {
+		in         string
+		isPositive bool
+	}{
+		{"[1 0] [3 0] [3 3] [1 3]", true},
+		{"[1 0] [1 3]", false}, // сквозь перекрытие
+	}
+
+	for _, tc := range testCases {
+		t.Run("ValidateRoute()", func(t *testing.T) {
+			if err := ValidateRoute(w, (&Route{}).Unserialize(tc.in)); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %s: %v", tc.in, err)
+			}
+		})
+	}
+
+	for _, in := range []string{
+		"w@ UQ\n---\nw   w", // наверху нет лестницы вниз
+		"w@ UQ",             // лестница выше верхнего этажа
+		"w@  Q\n---\nw  Dw", // лестница вниз без лестницы вверх
+	} {
+		if _, err := Construct(in); err == nil {
+			t.Errorf("Failure on %q: expected error", in)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+
+	w, err := Construct(`
+		ww=ww
+		=@ w=
+		w  wQ
+		ww=ww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	route := "[1 1] [4 1] [4 2]"
+	if err := ValidateRoute(w, (&Route{}).Unserialize(route)); err == nil {
+		t.Errorf("Failure on %s: edges do not wrap by default", route)
+	}
+	w.SetWrap(true)
+
+	for _, tc := range []struct {
+		from, to PointOnMap
+		isWrap   bool
+	}{
+		{PointOnMap{0, 1}, PointOnMap{4, 1}, true},
+		{PointOnMap{4, 1}, PointOnMap{0, 1}, true},
+		{PointOnMap{2, 0}, PointOnMap{2, 3}, true},
+		{PointOnMap{1, 1}, PointOnMap{4, 1}, true},  // напрямую мешает стена
+		{PointOnMap{2, 0}, PointOnMap{2, 2}, false}, // через край не короче
+		{PointOnMap{0, 0}, PointOnMap{4, 3}, false}, // по диагонали через край не ходят
+	} {
+		if isWrap := w.IsWrap(tc.from, tc.to); isWrap != tc.isWrap {
+			t.Errorf("Failure on IsWrap(%v, %v): expected %t, got %t", tc.from, tc.to, tc.isWrap, isWrap)
+		}
+	}
+
+	moves := w.FindNextMoves(1, 1, w.GetExitPoints())
+	if !slices.Contains(moves, GeoPosition{4, 1, w.SlideCost(PointOnMap{1, 1}, PointOnMap{4, 1})}) {
+		t.Errorf("Failure on FindNextMoves(): expected wrap to (4,1), got %v", moves)
+	}
+	folded := w.Fold(*(&Route{}).Unserialize("[1 1] [0 1] [4 1] [4 2]"))
+	if folded.Serialize() != route {
+		t.Errorf("Failure on Fold(): expected %s, got %s", route, folded.Serialize())
+	}
+
+	testCases := []struct {
+		in         string
+		isPositive bool
+	}{
+		{route, true},
+		{"[1 1] [0 1] [4 1] [4 2]", true},
+		{"[1 1] [4 1] [4 2] [4 1]", false},             // выход не в конце
+		{"[1 1] [2 1] [2 0] [2 3] [2 2] [4 2]", false}, // сквозь стену
+	}
+	for _, tc := range testCases {
+		t.Run("ValidateRoute()", func(t *testing.T) {
+			if err := ValidateRoute(w, (&Route{}).Unserialize(tc.in)); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %s: %v", tc.in, err)
+			}
+		})
+	}
+
+	cost := (&Route{}).Unserialize(route).CostBy(w.Rules())
+	if expected := (RouteCost{Moves: 2, Cells: 3, Turns: 1, Terrain: 4}); cost != expected {
+		t.Errorf("Failure on %s: expected %v, got %v", route, expected, cost)
+	}
+
+	w.SetMovement(MovementSlide)
+	if moves := w.FindNextMoves(1, 1, w.GetExitPoints()); !slices.ContainsFunc(moves, func(m GeoPosition) bool {
+		return m[0] == 4 && m[1] == 1
+	}) {
+		t.Errorf("Failure on FindNextMoves(): expected slide through the edge to (4,1), got %v", moves)
+	}
+
+	w.SetMovement(MovementStep)
+	if err := ValidateRoute(w, (&Route{}).Unserialize(route)); err == nil {
+		t.Errorf("Failure on %s: step movement crosses the edge only to the adjacent cell", route)
+	}
+}
+
+func TestHex(t *testing.T) {
+
+	w, err := Construct(`
+		wwwww
+		w@  w
+		w w w
+		w  Qw
+		wwwww
+
+		[grid]
+		hex
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.IsHex() {
+		t.Fatal("Failure: expected hex grid")
+	}
+
+	w.SetMovement(MovementStep)
+	moves := w.FindNextMoves(1, 1, nil)
+	if expected := []GeoPosition{{2, 1, 2}, {1, 2, 2}}; !slices.Equal(moves, expected) {
+		t.Errorf("Failure on FindNextMoves(): expected %v, got %v", expected, moves)
+	}
+
+	w.SetMovement(MovementRook)
+	testCases := []struct {
+		in         string
+		isPositive bool
+	}{
+		{"[1 1] [1 2] [1 3] [3 3]", true},
+		{"[1 1] [1 3] [3 3]", false}, // столбец - не прямая шестиугольной сетки
+		{"[1 1] [3 1] [3 3]", false},
+	}
+	for _, tc := range testCases {
+		t.Run("ValidateRoute()", func(t *testing.T) {
+			if err := ValidateRoute(w, (&Route{}).Unserialize(tc.in)); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %s: %v", tc.in, err)
+			}
+		})
+	}
+
+	if _, err := Construct("w@ Qw\n[grid]\ntriangle"); err == nil {
+		t.Errorf("Failure on [grid] triangle: expected error")
+	}
+}
+
+func TestParseCheckpoints(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwww
+		w@ !Qw
+		wwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (PointList{{3, 1}}); !slices.Equal(w.GetCheckpoints(), expected) {
+		t.Errorf("Failure on GetCheckpoints(): expected %v, got %v", expected, w.GetCheckpoints())
+	}
+
+	testCases := []struct {
+		in         string
+		isPositive bool
+	}{
+		{"3,1", true},
+		{"2,1;3,1", true},
+		{"0,1", false}, // стена
+		{"9,1", false},
+		{"3;1", false},
+		{"", false},
+	}
+	for _, tc := range testCases {
+		t.Run("ParseCheckpoints()", func(t *testing.T) {
+			if _, err := w.ParseCheckpoints(tc.in); tc.isPositive != (err == nil) {
+				t.Errorf("Failure on %q: %v", tc.in, err)
+			}
+		})
+	}
+
+	cells := w.RouteCells((&Route{}).Unserialize("[1 1] [4 1]"))
+	if len(cells) != 4 || !cells[PointOnMap{3, 1}] {
+		t.Errorf("Failure on RouteCells(): got %v", cells)
+	}
+}
+
+func TestGetItemPoints(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwwww
+		w@$ !Qw
+		w $w ww
+		wwwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (PointList{{2, 1}, {2, 2}}); !slices.Equal(w.GetItemPoints(), expected) {
+		t.Errorf("Failure on GetItemPoints(): expected %v, got %v", expected, w.GetItemPoints())
+	}
+	if expected := (PointList{{4, 1}}); !slices.Equal(w.GetCheckpoints(), expected) {
+		t.Errorf("Failure on GetCheckpoints(): expected %v, got %v", expected, w.GetCheckpoints())
+	}
+}
+
+func TestIsLoopless(t *testing.T) {
+
+	w, err := Construct(`
+		wwwwww
+		w@ !Qw
+		wwwwww
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !w.IsLoopless((&Route{}).Unserialize("[1 1] [2 1] [4 1]")) {
+		t.Errorf("Failure on IsLoopless(): expected no loop")
+	}
+	if w.IsLoopless((&Route{}).Unserialize("[1 1] [3 1] [2 1] [4 1]")) {
+		t.Errorf("Failure on IsLoopless(): expected a loop")
+	}
+}
@@ -0,0 +1,117 @@
+package world
+
+import (
+	. "maze/internal/global"
+)
+
+// Сквозные края: уйдя за край карты, попадаем на противоположный край той же
+// строки или столбца (на многоэтажной карте - того же этажа). Перемещение по
+// прямой продолжается за краем, а направление перемещения выбирает wrapMove.
+// По диагонали через край не ходят
+
+// SetWrap включает или выключает сквозные края
+func (w *World) SetWrap(wrap bool) {
+	w.wrap = wrap
+}
+
+func (w *World) Wraps() bool {
+	return w.wrap
+}
+
+// wrapTarget возвращает клетку на противоположном краю, если шаг из `p` в
+// направлении `dir` уходит за край. На карте шириной (высотой) в две клетки
+// шаг через край не отличить от обычного, такие края не сквозные
+func (w *World) wrapTarget(p PointOnMap, dir Direction) (PointOnMap, bool) {
+	if !w.wrap || w.hex || dir[0] != 0 && dir[1] != 0 || !w.inBounds(p[0], p[1]) {
+		return PointOnMap{}, false
+	}
+	p3 := w.To3D(p)
+	width, height := w.width, w.floorHeight(p3[2])
+	x, y := p3[0]+dir[0], p3[1]+dir[1]
+	if 0 <= x && x < width && 0 <= y && y < height || dir[0] != 0 && width < 3 || dir[1] != 0 && height < 3 {
+		return PointOnMap{}, false
+	}
+	return w.From3D(PointOnMap3D{(x + width) % width, (y + height) % height, p3[2]})
+}
+
+// wrapSize возвращает ширину карты и высоту этажа точки `p`
+func (w *World) wrapSize(p PointOnMap) (int, int) {
+	return w.width, w.floorHeight(w.To3D(p)[2])
+}
+
+// IsWrap проверяет, что перемещение по прямой идёт через край карты
+func (w *World) IsWrap(from, to PointOnMap) bool {
+	_, _, ok := w.wrapMove(from, to)
+	return ok
+}
+
+// wrapMove возвращает направление и число клеток перемещения через край
+// карты. Между точками одной строки (столбца) два пути - напрямую и через
+// край; через край идём, если этот путь открыт, а прямой закрыт или длиннее
+func (w *World) wrapMove(from, to PointOnMap) (Direction, int, bool) {
+	if !w.wrap || w.hex || from == to || from[0] != to[0] && from[1] != to[1] {
+		return Direction{}, 0, false
+	}
+	dir := WrapDirection(from, to)
+	cells, ok := w.walkLine(from, to, dir)
+	if !ok {
+		return Direction{}, 0, false
+	}
+	if cells >= CellsCost(from, to) {
+		if _, ok := w.walkLine(from, to, DirectionOf(from, to)); ok {
+			return Direction{}, 0, false
+		}
+	}
+	return dir, cells, true
+}
+
+// walkLine возвращает число клеток от `from` до `to` по прямой в направлении
+// `dir`. Путь закрыт, если упирается в стену или возвращается в `from`
+func (w *World) walkLine(from, to PointOnMap, dir Direction) (int, bool) {
+	cells := 0
+	for p := from; p != to; cells++ {
+		next := w.nextCell(p, dir)
+		if next == from || !w.canEnter(p, next, dir) {
+			return 0, false
+		}
+		p = next
+	}
+	return cells, true
+}
+
+// moveCells возвращает число клеток, пройденных при перемещении, с учётом
+// краёв карты и формы клеток
+func (w *World) moveCells(from, to PointOnMap) int {
+	if _, cells, ok := w.wrapMove(from, to); ok {
+		return cells
+	}
+	if w.hex {
+		return HexDistance(from, to)
+	}
+	return CellsCost(from, to)
+}
+
+// directionOf возвращает направление перемещения с учётом краёв карты и
+// формы клеток
+func (w *World) directionOf(from, to PointOnMap) Direction {
+	if dir, _, ok := w.wrapMove(from, to); ok {
+		return dir
+	}
+	if w.hex {
+		dir, _ := HexDirectionOf(from, to)
+		return dir
+	}
+	return DirectionOf(from, to)
+}
+
+// nextCell возвращает соседнюю клетку в направлении `dir`, за краем карты -
+// клетку на противоположном краю
+func (w *World) nextCell(p PointOnMap, dir Direction) PointOnMap {
+	if w.hex {
+		return HexNeighbor(p, dir)
+	}
+	if target, ok := w.wrapTarget(p, dir); ok {
+		return target
+	}
+	return PointOnMap{p[0] + dir[0], p[1] + dir[1]}
+}
@@ -5,150 +5,167 @@ import (
 	"flag"
 	"fmt"
 	"maze/internal/cli"
+	"maze/internal/global"
 	"maze/internal/navigator"
 	"maze/internal/world"
 	"os"
 	"strings"
-	"time"
 )
 
 const (
 	ExitTargetNotFound = 1
 	ExitError          = 2
+	ExitOutOfFuel      = 3 // выход достижим, но не с этим запасом топлива
 )
 
-const (
-	debugMode             = 0
-	defaultRouter         = navigator.RouterFox
-	defaultAnimationSpeed = 3
-	useTraceOnMove        = true
-)
+type command struct {
+	name        string
+	description string
+	run         func(args []string)
+}
 
-type configParams struct {
-	animateRoute        int
-	animationSpeed      int
-	routerType          string
-	stdinFlag           bool
-	debugAnimationFlag  bool
-	revertDirectionFlag bool
-	showRoutingTreeFlag bool
-	fromFile            string
+// commands Подкоманды. Без подкоманды выполняется solve
+var commands = []command{
+	{"solve", "find routes from start to exit", runSolve},
+	{"generate", "generate a maze in the map format", runGenerate},
+	{"validate", "validate a map and, optionally, a route", runValidate},
+	{"convert", "convert a map between text and json formats", runConvert},
+	{"bench", "compare routers across a map corpus", runBench},
 }
 
-var params configParams
-
-func initMain() {
-	animateRouteArg := flag.Int("r", -1, "animate route by number (if presented)")
-	animateSpeedArg := flag.Int("v", defaultAnimationSpeed, "set animation speed")
-	routerTypeArg := flag.String("t", defaultRouter, "router type: hare,deer,hog")
-	stdinFlagArg := flag.Bool("i", false, "read world from stdin")
-	debugAnimationArg := flag.Bool("D", false, "use debug animation (if provided by router)")
-	showRoutingTreeArg := flag.Bool("T", false, "show routing tree")
-	revertDirectionArg := flag.Bool("R", false, "swap start and finish")
-	fromFileArg := flag.String("f", "", "read world from file")
-
-	flag.Parse()
-
-	params = configParams{
-		animateRoute:        *animateRouteArg,
-		animationSpeed:      *animateSpeedArg,
-		routerType:          *routerTypeArg,
-		stdinFlag:           *stdinFlagArg,
-		debugAnimationFlag:  *debugAnimationArg,
-		revertDirectionFlag: *revertDirectionArg,
-		showRoutingTreeFlag: *showRoutingTreeArg,
-		fromFile:            *fromFileArg,
+const defaultCommand = "solve"
+
+func main() {
+
+	args := os.Args[1:]
+	name := defaultCommand
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
 
-	if isDebug() { // DEBUG
-		params.showRoutingTreeFlag = true
-		params.routerType = navigator.RouterFox
-		params.fromFile = "./maps/09.txt"
-		//params.revertDirectionFlag = true
-		//params.animateRoute = 0
+	for _, cmd := range commands {
+		if cmd.name == name {
+			cmd.run(args)
+			return
+		}
 	}
-}
 
-func main() {
+	if name == "help" {
+		printUsage(os.Stdout)
+		return
+	}
+	printUsage(os.Stderr)
+	os.Exit(ExitError)
+}
 
-	initMain()
-	cli.ClearScreen()
-	w := constructWorld(params)
-	if isDebug() {
-		fmt.Println(cli.WarnStyle("DEBUG MODE: ON"))
+func printUsage(out *os.File) {
+	_, _ = fmt.Fprintln(out, "Usage: maze <command> [flags]")
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, "Commands:")
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.description)
 	}
-	world.PrintMe(w)
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, "Run 'maze <command> -h' for command flags.")
+}
 
-	foundRoutes := navigator.FindRoutes(w, params.routerType)
+// newFlagSet создаёт набор флагов подкоманды со справкой
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: maze %s %s\n\nFlags:\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
 
-	if params.animateRoute != -1 {
-		if params.animateRoute < 0 || params.animateRoute >= len(foundRoutes) {
-			fatalExit("Route not found")
-		}
-		result := foundRoutes[params.animateRoute]
-		animate(w, result, params.animationSpeed, params.debugAnimationFlag)
-		fmt.Println()
-		fmt.Println("Routes:")
-		showRoutes(foundRoutes)
-		if !hasExit(foundRoutes) {
-			os.Exit(ExitTargetNotFound)
-		}
-		return
+func parseFlags(fs *flag.FlagSet, args []string) {
+	if err := fs.Parse(args); err != nil {
+		fatalExit(err)
 	}
+	if fs.NArg() > 0 {
+		fatalExit(fmt.Sprintf("unexpected arguments: %v", fs.Args()))
+	}
+}
+
+// worldSource Откуда загружать карту
+type worldSource struct {
+	fromFile            string
+	stdinFlag           bool
+	revertDirectionFlag bool
+	movement            string
+	breaks              int
+	wrapFlag            bool
+	fuel                int
+}
+
+func (ws *worldSource) register(fs *flag.FlagSet) {
+	fs.StringVar(&ws.fromFile, "f", "", "read world from file")
+	fs.BoolVar(&ws.stdinFlag, "i", false, "read world from stdin")
+	fs.BoolVar(&ws.revertDirectionFlag, "R", false, "swap start and finish")
+	fs.StringVar(&ws.movement, "move", string(global.MovementRook), "movement model: rook,slide,step,king")
+	fs.IntVar(&ws.breaks, "break", 0, "allow breaking up to K walls (mole router)")
+	fs.BoolVar(&ws.wrapFlag, "wrap", false, "wrap around map edges")
+	fs.IntVar(&ws.fuel, "fuel", 0, "fuel tank: each cell burns one unit, F refuels (mole router)")
+}
+
+func constructWorld(src worldSource) *world.World {
+
+	textWorld := loadTextWorld(src)
 
-	tree := navigator.BuildRoutingTree(w)
+	w, err := world.Construct(textWorld)
+	if err != nil {
+		fatalExit(err)
+	}
 
-	if params.showRoutingTreeFlag {
-		fmt.Println("Routing tree:")
-		navigator.PrintRoutingTree(tree)
-		if err := tree.Validate(); err != nil {
-			fmt.Println(" ", cli.ErrorStyle("Validation: FALSE"))
-			fmt.Println(err)
-		} else {
-			fmt.Println(" ", cli.ShadowStyle("Validation: TRUE"))
+	if src.movement != "" {
+		movement, err := global.ParseMovement(src.movement)
+		if err != nil {
+			fatalExit(err)
 		}
-		fmt.Println()
+		w.SetMovement(movement)
 	}
 
-	fmt.Println("Nodes:", len(tree))
-	fmt.Println("Router:", params.routerType)
-	fmt.Println("Routes:")
-	if len(foundRoutes) > 0 {
-		showRoutes(foundRoutes)
-		fmt.Print(cli.ShadowStyle("HELP: -r for animate route. Example:"))
-		fmt.Println(cli.ShadowStyle(" ./main -f path/to/map3.txt -v 5 -r 1"))
-	} else {
-		fmt.Println()
-		fmt.Println(" ", "No route!!")
-		fmt.Println()
+	if src.breaks < 0 {
+		fatalExit("-break must not be negative")
 	}
+	w.SetBreaks(src.breaks)
 
-	if !hasExit(foundRoutes) {
-		os.Exit(ExitTargetNotFound)
+	if src.wrapFlag && w.IsHex() {
+		fatalExit("-wrap is not supported on hex grids")
 	}
-}
+	w.SetWrap(src.wrapFlag)
 
-func hasExit(items []navigator.NavRoute) bool {
-	for _, n := range items {
-		if n.IsFoundTarget() {
-			return true
+	if src.fuel < 0 {
+		fatalExit("-fuel must not be negative")
+	}
+	if src.fuel > 0 && w.IsTimed() {
+		fatalExit("-fuel is not supported on maps with gates and guards")
+	}
+	w.SetFuel(src.fuel)
+
+	if src.revertDirectionFlag {
+		if len(w.GetExits()) > 1 || len(w.GetStarts()) > 1 {
+			fatalExit("-R requires a map with a single start and a single exit")
 		}
+		start, finish := w.GetStart(), w.GetExit()
+		w.SetStart(finish)
+		w.SetExit(start)
+		w.Revert()
 	}
-	return false
+	return w
 }
 
-func constructWorld(params configParams) *world.World {
-
-	fromFile := params.fromFile
-	fromStdin := params.stdinFlag
-	revertDirectionFlag := params.revertDirectionFlag
+// loadTextWorld возвращает карту в текстовом формате. Карта в формате JSON
+// (см. convert) переводится в текст
+func loadTextWorld(src worldSource) string {
 
 	var textWorld string
 
-	if fromStdin {
+	if src.stdinFlag {
 		textWorld = loadTextWorldFromStdin()
-	} else if fromFile != "" {
-		byteContent, err := os.ReadFile(fromFile)
+	} else if src.fromFile != "" {
+		byteContent, err := os.ReadFile(src.fromFile)
 		if err != nil {
 			fatalExit(err)
 		}
@@ -157,17 +174,14 @@ func constructWorld(params configParams) *world.World {
 		fatalExit("No world content. Use -f for load from file or -i for load from stdin")
 	}
 
-	w, err := world.Construct(textWorld)
-	if err != nil {
-		fatalExit(err)
-	}
-
-	if revertDirectionFlag {
-		start, finish := w.GetStart(), w.GetExit()
-		w.SetStart(finish)
-		w.SetExit(start)
+	if isJsonMap(textWorld) {
+		lines, err := jsonMapToLines(textWorld)
+		if err != nil {
+			fatalExit(err)
+		}
+		textWorld = strings.Join(lines, "\n")
 	}
-	return w
+	return textWorld
 }
 
 func loadTextWorldFromStdin() string {
@@ -192,84 +206,29 @@ func loadTextWorldFromStdin() string {
 	return strings.Join(lines, "\n")
 }
 
-func fatalExit(e interface{}) {
-	_, _ = fmt.Fprintf(os.Stderr, "FATAL: %v\n", e)
-	os.Exit(ExitError)
-}
-
-func animate(w *world.World, result navigator.NavRoute, speed int, useFrameAnimation bool) {
-
-	cli.ClearScreen()
-	speedValue := time.Duration(speed)
-	cmdString := cli.GetExecutedCommand()
-
-	route := *result.Route
-
-	for i, node := range route.GetItems() {
-		if err := w.Move(node[0], node[1], useTraceOnMove); err != nil {
-			panic(node)
-		}
-
-		lineForShow := 1
-		cli.SetCursorPosition(0, 0)
-		if isDebug() {
-			fmt.Println(cli.WarnStyle("DEBUG MODE: ON"))
-			lineForShow++
-		}
-		fmt.Println("Executed:", cmdString)
-		world.PrintMe(w)
-		fmt.Println("Router:", result.RouterName)
-		fmt.Println(" ", route.GetItems()[:i+1])
-		time.Sleep(time.Millisecond * 1500 / speedValue)
-
-		if !useFrameAnimation {
-			continue
+func hasExit(items []navigator.NavRoute) bool {
+	for _, n := range items {
+		if n.IsFoundTarget() {
+			return true
 		}
-
-		func(iFrame int) {
-			wData := w.Pack()
-			for k := 0; k < 2; k++ {
-
-				if l := len(result.RecRouteFrames); 0 < l && iFrame < l-1 {
-					frm := result.RecRouteFrames[iFrame]
-					frmMin, frmMax := frm[0], frm[1]
-					frmMaxX, frmMaxY := frmMax[0]-1, frmMax[1]-1
-					frmMinX, frmMinY := frmMin[0]+1, frmMin[1]+1
-					w.SetRectangle(frmMinX, frmMinY, frmMaxX, frmMaxY)
-				}
-
-				if l := len(result.RecPointLists); 0 < l && iFrame < l-1 {
-					for _, p := range result.RecPointLists[iFrame] {
-						w.SetPoint(p[0], p[1], '?')
-					}
-
-					cli.SetCursorPosition(0, lineForShow)
-					world.PrintMapOnly(w)
-					time.Sleep(time.Millisecond * 1000 / speedValue)
-
-					w.Unpack(wData)
-					cli.SetCursorPosition(0, lineForShow)
-					world.PrintMapOnly(w)
-					time.Sleep(time.Millisecond * 1000 / speedValue)
-				}
-			}
-			w.Unpack(wData)
-		}(i)
 	}
+	return false
 }
 
-func showRoutes(results []navigator.NavRoute) {
-	for n, route := range results {
-		validationSign := cli.ShadowStyle("Validation: TRUE")
-		if err := route.Route.Validate(); err != nil {
-			validationSign = cli.ErrorStyle("Validation: FALSE")
-		}
-		fmt.Println()
-		fmt.Println(" #", n, ":", route, validationSign)
+// exitIfNotFound завершает работу, если ни один маршрут не дошёл до выхода.
+// Если выход недостижим только из-за топлива, код выхода отдельный
+func exitIfNotFound(w *world.World, items []navigator.NavRoute) {
+	if hasExit(items) {
+		return
+	}
+	if navigator.IsOutOfFuel(w) {
+		_, _ = fmt.Fprintf(os.Stderr, "Exit is unreachable with fuel %d\n", w.Fuel())
+		os.Exit(ExitOutOfFuel)
 	}
-	fmt.Println()
+	os.Exit(ExitTargetNotFound)
 }
 
-func isDebug() bool {
-	return debugMode != 0
+func fatalExit(e interface{}) {
+	_, _ = fmt.Fprintf(os.Stderr, "FATAL: %v\n", e)
+	os.Exit(ExitError)
 }
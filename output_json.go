@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"maze/internal/global"
+	"maze/internal/navigator"
+	"maze/internal/world"
+	"os"
+)
+
+type jsonDocument struct {
+	Map     jsonMap          `json:"map"`
+	Start   [2]int           `json:"start"`
+	Starts  global.PointList `json:"starts"`
+	Nearest int              `json:"nearestStart"` // ближайший к выходу старт, -1 если нет
+	Exit    [2]int           `json:"exit"`
+	Exits   global.PointList `json:"exits"`
+	Router  string           `json:"router"`
+	Metric  global.Metric    `json:"metric"`
+	Best    int              `json:"best"`
+	Routes  []jsonRoute      `json:"routes"`
+	Tree    []jsonNode       `json:"tree,omitempty"`
+}
+
+type jsonMap struct {
+	Width  int  `json:"width"`
+	Height int  `json:"height"`
+	Floors int  `json:"floors,omitempty"`
+	Hex    bool `json:"hex,omitempty"`
+}
+
+type jsonRoute struct {
+	Points          []global.PointOnMap   `json:"points"`
+	Points3D        []global.PointOnMap3D `json:"points3d,omitempty"`
+	Start           global.PointOnMap     `json:"start"`
+	Exit            global.PointOnMap     `json:"exit"`
+	Keys            string                `json:"keys,omitempty"`
+	Broken          global.PointList      `json:"broken,omitempty"`
+	Time            int                   `json:"time,omitempty"`
+	Fuel            []int                 `json:"fuel,omitempty"`
+	Via             global.PointList      `json:"via,omitempty"`
+	Length          int                   `json:"length"`
+	Cost            global.RouteCost      `json:"cost"`
+	FoundTarget     bool                  `json:"foundTarget"`
+	Valid           bool                  `json:"valid"`
+	ValidationError string                `json:"validationError,omitempty"`
+}
+
+type jsonNode struct {
+	Point global.PointOnMap `json:"point"`
+	Next  global.PointList  `json:"next"`
+}
+
+// printJson выводит результат одним JSON документом, без цветов и очистки экрана
+func printJson(w *world.World, routerName string, routes []navigator.NavRoute, opts navigator.Options, withTree bool) {
+
+	width, height := w.GetSizes()
+	floors := 0
+	if w.GetFloors() > 1 {
+		floors = w.GetFloors()
+	}
+	doc := jsonDocument{
+		Map:     jsonMap{Width: width, Height: height, Floors: floors, Hex: w.IsHex()},
+		Start:   w.GetStart().ToArray(),
+		Starts:  w.GetStartPoints(),
+		Nearest: -1,
+		Exit:    w.GetExit().ToArray(),
+		Exits:   w.GetExitPoints(),
+		Router:  routerName,
+		Metric:  opts.Metric,
+		Best:    navigator.SelectBest(routes, opts.Metric),
+		Routes:  make([]jsonRoute, 0, len(routes)),
+	}
+	if len(doc.Exits) > 0 {
+		doc.Nearest = navigator.SelectBest(navigator.FindStartRoutes(w, opts), opts.Metric)
+	}
+
+	for _, route := range routes {
+		item := jsonRoute{
+			Points:      append([]global.PointOnMap{}, route.Route.GetItems()...),
+			Start:       route.Start,
+			Exit:        route.Exit,
+			Keys:        route.Keys,
+			Broken:      route.Broken,
+			Time:        route.Time,
+			Fuel:        route.Fuel,
+			Via:         route.Via,
+			Length:      route.Route.GetLength(),
+			Cost:        route.Cost,
+			FoundTarget: route.IsFoundTarget(),
+			Valid:       true,
+		}
+		if w.GetFloors() > 1 {
+			for _, p := range item.Points {
+				item.Points3D = append(item.Points3D, w.To3D(p))
+			}
+		}
+		if err := world.ValidateRoute(w, route.Route); err != nil {
+			item.Valid = false
+			item.ValidationError = err.Error()
+		}
+		doc.Routes = append(doc.Routes, item)
+	}
+
+	if withTree {
+		tree := navigator.BuildRoutingTree(w)
+		keys := tree.ToKeys()
+		keys.Sort()
+		for _, point := range keys {
+			doc.Tree = append(doc.Tree, jsonNode{
+				Point: point,
+				Next:  append(global.PointList{}, tree[point]...),
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		fatalExit(err)
+	}
+}